@@ -5,13 +5,19 @@ import (
 	"TestEffectiveMobile/internal/config"
 	"TestEffectiveMobile/pkg/logger"
 	"context"
+	"go.uber.org/zap"
+	"os"
 )
 
 // @title Сервис подписок API
 // @version 1.0.0
 // @description REST-сервис для агрегации данных об онлайн-подписках пользователей.
 // @host localhost:4047
-// @BasePath /api/v1
+// @BasePath /api
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Токен администратора в виде "Bearer <ADMIN_TOKEN>"
 
 func main() {
 	ctx := context.Background()
@@ -23,6 +29,24 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(cfg, ctx, os.Args[2:]); err != nil {
+			logger.GetLoggerFromCtx(ctx).Fatal("migrate failed", zap.Error(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "purge" {
+		if err := app.Purge(cfg, ctx, os.Args[2:]); err != nil {
+			logger.GetLoggerFromCtx(ctx).Fatal("purge failed", zap.Error(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := app.Import(cfg, ctx, os.Args[2:]); err != nil {
+			logger.GetLoggerFromCtx(ctx).Fatal("import failed", zap.Error(err))
+		}
+		return
+	}
 	newApp := app.New(cfg, ctx)
 	newApp.MustRun()
 }
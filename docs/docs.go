@@ -15,7 +15,7 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/create": {
+        "/v1/create": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -27,6 +27,7 @@ const docTemplate = `{
                     "Подписки"
                 ],
                 "summary": "Создаёт новую подписку",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Данные для создания подписки",
@@ -36,6 +37,20 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.CreateSubscription"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "3f1c2a9e-7b4d-4e0a-9c55-1d2e3f4a5b6c",
+                        "description": "Ключ идемпотентности: повтор запроса с тем же ключом и телом возвращает исходный ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -43,10 +58,16 @@ const docTemplate = `{
                         "description": "Id созданной подписки",
                         "schema": {
                             "$ref": "#/definitions/models.ID"
+                        },
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true, если ответ повторён по ключу идемпотентности"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
                         "schema": {
                             "$ref": "#/definitions/models.BadResponse"
                         }
@@ -57,6 +78,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BadResponse"
                         }
                     },
+                    "409": {
+                        "description": "Подписка уже существует или запрос с тем же ключом идемпотентности ещё выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса с ключом идемпотентности больше 4 МиБ",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушено ограничение целостности или ключ идемпотентности использован с другим телом запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -66,8 +105,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/delete/{id}": {
+        "/v1/delete/{id}": {
             "delete": {
+                "description": "Подписка помечается удалённой и не попадает в выдачу и суммы, её можно восстановить до очистки командой purge",
                 "consumes": [
                     "application/json"
                 ],
@@ -78,6 +118,7 @@ const docTemplate = `{
                     "Подписки"
                 ],
                 "summary": "Удаляет подписку по id",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -87,6 +128,20 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"1\"",
+                        "description": "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -97,7 +152,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
                         "schema": {
                             "$ref": "#/definitions/models.BadResponse"
                         }
@@ -114,6 +169,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BadResponse"
                         }
                     },
+                    "412": {
+                        "description": "Подписка изменена другим запросом, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match, когда он обязателен",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -123,7 +190,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/list/{user_id}": {
+        "/v1/list/{user_id}": {
             "get": {
                 "consumes": [
                     "application/json"
@@ -135,6 +202,7 @@ const docTemplate = `{
                     "Подписки"
                 ],
                 "summary": "Возвращает список подписок пользователя",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -147,13 +215,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список подписок пользователя",
+                        "description": "Список подписок пользователя, цены в основных единицах валюты",
                         "schema": {
-                            "$ref": "#/definitions/models.ListSubscriptionsResponse"
+                            "$ref": "#/definitions/models.ListSubscriptionsV1Response"
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
                         "schema": {
                             "$ref": "#/definitions/models.BadResponse"
                         }
@@ -179,8 +247,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/read/{id}": {
+        "/v1/read/{id}": {
             "get": {
+                "description": "Цена возвращается целым числом в основных единицах валюты, копейки отбрасываются",
                 "consumes": [
                     "application/json"
                 ],
@@ -191,6 +260,7 @@ const docTemplate = `{
                     "Подписки"
                 ],
                 "summary": "Получает подписку по id",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -206,11 +276,17 @@ const docTemplate = `{
                     "200": {
                         "description": "Подписка",
                         "schema": {
-                            "$ref": "#/definitions/models.Subscription"
+                            "$ref": "#/definitions/models.SubscriptionV1"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
                         "schema": {
                             "$ref": "#/definitions/models.BadResponse"
                         }
@@ -236,8 +312,121 @@ const docTemplate = `{
                 }
             }
         },
-        "/sum": {
+        "/v1/subscriptions/{id}": {
+            "patch": {
+                "description": "Изменяются только переданные поля, пустая строка в end_date делает подписку бессрочной",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Частично обновляет подписку по id",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Поля подписки для изменения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSubscription"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"1\"",
+                        "description": "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённая подписка, цена в основных единицах валюты",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionV1"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Конфликт с существующей подпиской",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена другим запросом, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушено ограничение целостности",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match, когда он обязателен",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/sum": {
             "get": {
+                "description": "Стоимость каждой подписки умножается на число месяцев её пересечения с периодом [start_date, end_date].\nСумма возвращается целым числом в основных единицах валюты, копейки отбрасываются. Подписки в разных валютах складываются только\nпри переданном currency: стоимость каждого месяца пересчитывается по курсу, действующему в этом месяце.",
                 "consumes": [
                     "application/json"
                 ],
@@ -248,6 +437,7 @@ const docTemplate = `{
                     "Подписки"
                 ],
                 "summary": "Возвращает сумму подписок пользователя",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -282,17 +472,35 @@ const docTemplate = `{
                         "name": "service_name",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "RUB",
+                        "description": "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "charged",
+                            "amortized"
+                        ],
+                        "type": "string",
+                        "default": "charged",
+                        "description": "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Сумма подписок пользователя",
+                        "description": "Сумма подписок пользователя и число оплачиваемых месяцев",
                         "schema": {
-                            "$ref": "#/definitions/models.SumSubscriptionsResponse"
+                            "$ref": "#/definitions/models.SumSubscriptionsV1Response"
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
                         "schema": {
                             "$ref": "#/definitions/models.BadResponse"
                         }
@@ -309,6 +517,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BadResponse"
                         }
                     },
+                    "422": {
+                        "description": "Подписки оплачиваются в разных валютах или нет курса для пересчёта",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -318,7 +532,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/update/{id}": {
+        "/v1/update/{id}": {
             "put": {
                 "consumes": [
                     "application/json"
@@ -330,6 +544,7 @@ const docTemplate = `{
                     "Подписки"
                 ],
                 "summary": "Обновляет подписку по id",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -348,6 +563,20 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.UpdateSubscription"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"1\"",
+                        "description": "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -355,10 +584,16 @@ const docTemplate = `{
                         "description": "Подписка обновлена",
                         "schema": {
                             "$ref": "#/definitions/models.GoodResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
                         "schema": {
                             "$ref": "#/definitions/models.BadResponse"
                         }
@@ -375,6 +610,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BadResponse"
                         }
                     },
+                    "409": {
+                        "description": "Конфликт с существующей подпиской",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена другим запросом, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушено ограничение целостности",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match, когда он обязателен",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -383,90 +642,2426 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.BadResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.CreateSubscription": {
-            "type": "object",
-            "properties": {
-                "end_date": {
-                    "type": "string"
-                },
-                "price": {
-                    "type": "integer"
-                },
-                "service_name": {
-                    "type": "string"
-                },
-                "start_date": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.GoodResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.ID": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                }
-            }
         },
-        "models.ListSubscriptionsResponse": {
-            "type": "object",
-            "properties": {
-                "subscriptions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Subscription"
+        "/v2/admin/exchange-rates": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование"
+                ],
+                "summary": "Возвращает все курсы валют",
+                "responses": {
+                    "200": {
+                        "description": "Курсы валют",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExchangeRatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Нет или неверный токен администратора",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Токен администратора не настроен, маршруты администрирования отключены",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
                     }
                 }
-            }
-        },
-        "models.Subscription": {
-            "type": "object",
-            "properties": {
-                "end_date": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "price": {
-                    "type": "integer"
-                },
-                "service_name": {
-                    "type": "string"
-                },
-                "start_date": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Принимает JSON-массив курсов или CSV с заголовком base,quote,rate,effective_from.\nКурс действует с месяца effective_from до следующего курса той же пары, существующий курс перезаписывается.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Администрирование"
+                ],
+                "summary": "Загружает курсы валют",
+                "parameters": [
+                    {
+                        "description": "Курсы валют",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExchangeRate"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сохранённые курсы",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExchangeRatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Нет или неверный токен администратора",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Токен администратора не настроен, маршруты администрирования отключены",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/reports/aggregate": {
+            "get": {
+                "description": "Для каждой группы считаются количество подписок, сумма, средняя, минимальная и максимальная стоимость подписки за период",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Отчёты"
+                ],
+                "summary": "Возвращает расходы на подписки, сгруппированные по сервису, пользователю или месяцу",
+                "parameters": [
+                    {
+                        "enum": [
+                            "service_name",
+                            "user_id",
+                            "month"
+                        ],
+                        "type": "string",
+                        "description": "Поле группировки",
+                        "name": "group_by",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"user12345\"",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"YouTube\"",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "01-2025",
+                        "description": "Месяц начала периода",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "12-2025",
+                        "description": "Месяц окончания периода",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "RUB",
+                        "description": "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "charged",
+                            "amortized"
+                        ],
+                        "type": "string",
+                        "default": "charged",
+                        "description": "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "key",
+                            "-key",
+                            "count",
+                            "-count",
+                            "sum",
+                            "-sum",
+                            "avg",
+                            "-avg",
+                            "min",
+                            "-min",
+                            "max",
+                            "-max"
+                        ],
+                        "type": "string",
+                        "default": "-sum",
+                        "description": "Поле сортировки групп, - для убывания",
+                        "name": "order_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Количество первых групп (0-100), 0 - все группы",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сгруппированные расходы",
+                        "schema": {
+                            "$ref": "#/definitions/models.AggregateReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Подписки оплачиваются в разных валютах или нет курса для пересчёта",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/reports/monthly": {
+            "get": {
+                "description": "Для каждого месяца периода возвращается сумма и разбивка по сервисам, фильтры те же, что у суммы подписок",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Отчёты"
+                ],
+                "summary": "Возвращает помесячную разбивку расходов на подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"user12345\"",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"YouTube\"",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "01-2025",
+                        "description": "Месяц начала периода",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "12-2025",
+                        "description": "Месяц окончания периода",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "RUB",
+                        "description": "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "charged",
+                            "amortized"
+                        ],
+                        "type": "string",
+                        "default": "charged",
+                        "description": "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Помесячный отчёт",
+                        "schema": {
+                            "$ref": "#/definitions/models.MonthlyReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Подписки оплачиваются в разных валютах или нет курса для пересчёта",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/reports/sum": {
+            "get": {
+                "description": "Стоимость каждой подписки умножается на число месяцев её пересечения с периодом [start_date, end_date].\nСумма возвращается в минимальных единицах валюты. Подписки в разных валютах складываются только\nпри переданном currency: стоимость каждого месяца пересчитывается по курсу, действующему в этом месяце.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Возвращает сумму подписок пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"user12345\"",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date",
+                        "example": "01-2006",
+                        "description": "Дата начала периода",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date",
+                        "example": "01-2006",
+                        "description": "Дата окончания периода",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"YouTube\"",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "RUB",
+                        "description": "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "charged",
+                            "amortized"
+                        ],
+                        "type": "string",
+                        "default": "charged",
+                        "description": "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сумма подписок пользователя и число оплачиваемых месяцев",
+                        "schema": {
+                            "$ref": "#/definitions/models.SumSubscriptionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Подписки оплачиваются в разных валютах или нет курса для пересчёта",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions": {
+            "get": {
+                "description": "Постраничная выдача по курсору: следующая страница запрашивается с cursor из next_cursor",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Возвращает страницу подписок с фильтрацией и сортировкой",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная цена в минимальных единицах валюты",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная цена в минимальных единицах валюты",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "RUB",
+                        "description": "Валюта (ISO 4217)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "weekly",
+                            "monthly",
+                            "quarterly",
+                            "yearly"
+                        ],
+                        "type": "string",
+                        "description": "Период оплаты",
+                        "name": "billing_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "06-2025",
+                        "description": "Подписка активна в месяце",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "01-2025",
+                        "description": "Начало подписки не раньше",
+                        "name": "start_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "12-2025",
+                        "description": "Начало подписки не позже",
+                        "name": "start_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "01-2025",
+                        "description": "Окончание подписки не раньше",
+                        "name": "end_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "12-2025",
+                        "description": "Окончание подписки не позже",
+                        "name": "end_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "price",
+                            "-price",
+                            "start_date",
+                            "-start_date",
+                            "service_name",
+                            "-service_name"
+                        ],
+                        "type": "string",
+                        "description": "Поле сортировки, - для убывания",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница подписок",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListSubscriptionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Создаёт новую подписку",
+                "parameters": [
+                    {
+                        "description": "Данные для создания подписки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSubscription"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "3f1c2a9e-7b4d-4e0a-9c55-1d2e3f4a5b6c",
+                        "description": "Ключ идемпотентности: повтор запроса с тем же ключом и телом возвращает исходный ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            },
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true, если ответ повторён по ключу идемпотентности"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Адрес созданной подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка уже существует или запрос с тем же ключом идемпотентности ещё выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса с ключом идемпотентности больше 4 МиБ",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушено ограничение целостности или ключ идемпотентности использован с другим телом запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/bulk/create": {
+            "post": {
+                "description": "В режиме atomic подписки создаются все или ни одной, в режиме best_effort создаются все, для которых это возможно.\nКаждый элемент проверяется как при создании одной подписки, в results - статус, который получил бы отдельный запрос.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пакетные операции"
+                ],
+                "summary": "Создаёт пакет подписок",
+                "parameters": [
+                    {
+                        "description": "Режим и подписки для создания (не более 1000)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkCreateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "3f1c2a9e-7b4d-4e0a-9c55-1d2e3f4a5b6c",
+                        "description": "Ключ идемпотентности: повтор запроса с тем же ключом и телом возвращает исходный ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Все подписки созданы",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "В режиме best_effort часть подписок не создана, ошибка каждой - в results",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Запрос с тем же ключом идемпотентности ещё выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Тело запроса с ключом идемпотентности больше 4 МиБ",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "В режиме atomic подписка не создана и пакет отменён, ошибка каждой - в results",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/bulk/delete": {
+            "post": {
+                "description": "Каждый элемент удаляет подписку id как DELETE, version работает как заголовок If-Match.\nВ режиме atomic удаляются все подписки или ни одной, в режиме best_effort удаляются все, для которых это возможно.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пакетные операции"
+                ],
+                "summary": "Удаляет пакет подписок",
+                "parameters": [
+                    {
+                        "description": "Режим и подписки для удаления (не более 1000)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkDeleteRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Все подписки удалены",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "В режиме best_effort часть подписок не удалена, ошибка каждой - в results",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "В режиме atomic подписка не удалена и пакет отменён, ошибка каждой - в results",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/bulk/update": {
+            "post": {
+                "description": "Каждый элемент частично изменяет подписку id как PATCH, version работает как заголовок If-Match.\nВ режиме atomic изменения применяются все или ни одного, в режиме best_effort применяются все, для которых это возможно.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пакетные операции"
+                ],
+                "summary": "Изменяет пакет подписок",
+                "parameters": [
+                    {
+                        "description": "Режим и изменения подписок (не более 1000)",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkUpdateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Все подписки изменены",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "В режиме best_effort часть подписок не изменена, ошибка каждой - в results",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "В режиме atomic подписка не изменена и пакет отменён, ошибка каждой - в results",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/export": {
+            "get": {
+                "description": "Подписки, подходящие под фильтры, передаются потоком без постраничной выдачи. Формат выбирается заголовком Accept:\ntext/csv (по умолчанию, цена в основных единицах валюты, как при импорте) или application/x-ndjson (подписка в формате JSON на каждой строке).",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Выгружает подписки в CSV или NDJSON",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная цена в минимальных единицах валюты",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная цена в минимальных единицах валюты",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "RUB",
+                        "description": "Валюта (ISO 4217)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "weekly",
+                            "monthly",
+                            "quarterly",
+                            "yearly"
+                        ],
+                        "type": "string",
+                        "description": "Период оплаты",
+                        "name": "billing_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "06-2025",
+                        "description": "Подписка активна в месяце",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "01-2025",
+                        "description": "Начало подписки не раньше",
+                        "name": "start_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "12-2025",
+                        "description": "Начало подписки не позже",
+                        "name": "start_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "01-2025",
+                        "description": "Окончание подписки не раньше",
+                        "name": "end_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "12-2025",
+                        "description": "Окончание подписки не позже",
+                        "name": "end_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "price",
+                            "-price",
+                            "start_date",
+                            "-start_date",
+                            "service_name",
+                            "-service_name"
+                        ],
+                        "type": "string",
+                        "description": "Поле сортировки, - для убывания",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписки в выбранном формате",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "Имя файла выгрузки, например attachment; filename=\\\"subscriptions-20250101.csv\\"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Запрошенный в Accept формат не поддерживается",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/import": {
+            "post": {
+                "description": "Файл передаётся в поле file, первая строка - заголовок со столбцами service_name,price,user_id,start_date\nи необязательными currency,billing_period,end_date в любом порядке. Цена указывается в основных единицах валюты, например 399.99.\nКаждая строка проверяется как при создании подписки, строки с ошибками пропускаются и перечисляются в errors,\nостальные создаются одним пакетом. С dry_run=true подписки только проверяются.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Пакетные операции"
+                ],
+                "summary": "Импортирует подписки из CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV-файл с подписками (не более 10000 строк)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Только проверить строки, не создавая подписки",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат проверки в режиме dry_run",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "201": {
+                        "description": "Все строки импортированы",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "207": {
+                        "description": "Часть строк импортирована, ошибки остальных - в errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса или файла, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Ни одна строка не импортирована, ошибки строк - в errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/{id}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Получает подписку по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Передаются все поля подписки, отсутствие end_date делает подписку бессрочной",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Заменяет подписку по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные подписки",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSubscription"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"1\"",
+                        "description": "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённая подписка",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Конфликт с существующей подпиской",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена другим запросом, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушено ограничение целостности",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match, когда он обязателен",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Подписка помечается удалённой и не попадает в выдачу и суммы, её можно восстановить до очистки командой purge",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Удаляет подписку по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"1\"",
+                        "description": "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка удалена"
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена другим запросом, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match, когда он обязателен",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Изменяются только переданные поля, пустая строка в end_date делает подписку бессрочной",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Частично обновляет подписку по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Поля подписки для изменения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSubscription"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"1\"",
+                        "description": "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённая подписка",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Конфликт с существующей подпиской",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена другим запросом, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушено ограничение целостности",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан заголовок If-Match, когда он обязателен",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/{id}/history": {
+            "get": {
+                "description": "Записи о создании, изменениях, изменениях цены и удалении подписки в порядке их выполнения, журнал доступен и после удаления подписки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Возвращает журнал изменений подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Журнал изменений",
+                        "schema": {
+                            "$ref": "#/definitions/models.HistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/{id}/prices": {
+            "get": {
+                "description": "Первая запись - цена с месяца начала подписки, далее изменения цены, включая запланированные",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Возвращает историю цен подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "История цен",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Новая цена действует с месяца effective_from до следующего изменения, суммы за прошлые месяцы считаются по прежней цене.\nИзменение в том же месяце перезаписывается, без валюты сохраняется текущая валюта подписки.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Планирует изменение цены подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая цена и месяц, с которого она действует",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceChange"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Запланированное изменение цены",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceChange"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушено ограничение целостности",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/subscriptions/{id}/restore": {
+            "post": {
+                "description": "Удалённая подписка хранится до очистки командой purge",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Восстанавливает удалённую подписку по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "example": "550e8400-e29b-41d4-a716-446655440000",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "billing-service",
+                        "description": "Кто выполняет изменение, записывается в журнал изменений",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Восстановленная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка не удалена",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/users/{user_id}/subscriptions": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Подписки"
+                ],
+                "summary": "Возвращает список подписок пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"user12345\"",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список подписок пользователя",
+                        "schema": {
+                            "$ref": "#/definitions/models.ListSubscriptionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, в errors перечислены ошибочные поля",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Метод не разрешён",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.BadResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AggregateGroup": {
+            "type": "object",
+            "properties": {
+                "avg": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "max": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "integer"
+                },
+                "sum": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AggregateReport": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AggregateGroup"
+                    }
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "price_change",
+                        "restore",
+                        "purge"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "changed_at": {
+                    "type": "string",
+                    "example": "2025-09-20T10:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "7f9c0b1e-3a5d-4c2e-9b8a-1d2e3f4a5b6c"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "models.BadResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/suberrors.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/create"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Validation failed"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/problems/validation-error"
+                }
+            }
+        },
+        "models.BulkCreateRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateSubscription"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "example": "atomic"
+                }
+            }
+        },
+        "models.BulkDeleteItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.BulkDeleteRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkDeleteItem"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "example": "atomic"
+                }
+            }
+        },
+        "models.BulkItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/models.BadResponse"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "subscription": {
+                    "$ref": "#/definitions/models.Subscription"
+                }
+            }
+        },
+        "models.BulkResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkItemResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkUpdateItem": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.BulkUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkUpdateItem"
+                    }
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "example": "atomic"
+                }
+            }
+        },
+        "models.CreateSubscription": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 92.5
+                }
+            }
+        },
+        "models.ExchangeRatesResponse": {
+            "type": "object",
+            "properties": {
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExchangeRate"
+                    }
+                }
+            }
+        },
+        "models.GoodResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEntry"
+                    }
+                }
+            }
+        },
+        "models.ID": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "valid": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/suberrors.FieldError"
+                    }
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.ListSubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Subscription"
+                    }
+                }
+            }
+        },
+        "models.ListSubscriptionsV1Response": {
+            "type": "object",
+            "properties": {
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionV1"
+                    }
+                }
+            }
+        },
+        "models.MonthTotal": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string"
+                },
+                "services": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ServiceTotal"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MonthlyReport": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MonthTotal"
+                    }
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PriceChange": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "type": "string",
+                    "example": "01-2026"
+                },
+                "price": {
+                    "$ref": "#/definitions/money.Money"
+                }
+            }
+        },
+        "models.PriceHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceChange"
+                    }
+                }
+            }
+        },
+        "models.ServiceTotal": {
+            "type": "object",
+            "properties": {
+                "service_name": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Subscription": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.SubscriptionV1": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 399
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
         "models.SumSubscriptionsResponse": {
             "type": "object",
             "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "months": {
+                    "type": "integer"
+                },
+                "sum": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SumSubscriptionsV1Response": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "months": {
+                    "type": "integer"
+                },
                 "sum": {
                     "type": "integer"
                 }
@@ -475,11 +3070,14 @@ const docTemplate = `{
         "models.UpdateSubscription": {
             "type": "object",
             "properties": {
+                "billing_period": {
+                    "type": "string"
+                },
                 "end_date": {
                     "type": "string"
                 },
                 "price": {
-                    "type": "integer"
+                    "$ref": "#/definitions/money.Money"
                 },
                 "service_name": {
                     "type": "string"
@@ -488,6 +3086,41 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "money.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 39900
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                }
+            }
+        },
+        "suberrors.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Токен администратора в виде \"Bearer \u003cADMIN_TOKEN\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -496,7 +3129,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0.0",
 	Host:             "localhost:4047",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "Сервис подписок API",
 	Description:      "REST-сервис для агрегации данных об онлайн-подписках пользователей.",
@@ -8,6 +8,8 @@ import (
 	"TestEffectiveMobile/pkg/logger"
 	"TestEffectiveMobile/pkg/postgres"
 	"context"
+	"errors"
+	"fmt"
 	"go.uber.org/zap"
 	"os"
 	"os/signal"
@@ -21,20 +23,46 @@ type App struct {
 	ctx                context.Context
 	wg                 sync.WaitGroup
 	cancel             context.CancelFunc
+	closeStorage       func()
 }
 
+// New wires the application. The ctx given to the repository, service and
+// server is not cancelled on shutdown, so in-flight requests can still reach
+// the database while the server drains; only the App's own root context is.
 func New(cfg *config.Config, ctx context.Context) *App {
-	db, err := postgres.New(cfg.Postgres)
-	if err != nil {
-		panic(err)
+	var repo repository.SubscriptionRepositoryInterface
+	closeStorage := func() {}
+	switch cfg.Storage {
+	case config.StoragePostgres:
+		if cfg.AutoMigrate {
+			if err := MigrateUp(cfg, ctx); err != nil {
+				panic(err)
+			}
+		}
+		db, err := postgres.New(cfg.Postgres)
+		if err != nil {
+			panic(err)
+		}
+		repo = repository.NewSubscriptionRepository(db, ctx)
+		closeStorage = db.Close
+	case config.StorageMemory:
+		logger.GetLoggerFromCtx(ctx).Warn("using in-memory storage, data will be lost on restart")
+		repo = repository.NewMemorySubscriptionRepository()
+	default:
+		panic(fmt.Sprintf("unknown storage backend: %q", cfg.Storage))
+	}
+	if cfg.AdminToken == "" {
+		logger.GetLoggerFromCtx(ctx).Warn("ADMIN_TOKEN is not set, admin endpoints are not protected")
 	}
-	repo := repository.NewSubscriptionRepository(db, ctx)
 	srv := service.NewSubscriptionService(repo, cfg, ctx)
 	server := transport.New(srv, cfg, ctx)
+	rootCtx, cancel := context.WithCancel(ctx)
 	return &App{
 		SubscriptionServer: server,
 		cfg:                cfg,
-		ctx:                ctx,
+		ctx:                rootCtx,
+		cancel:             cancel,
+		closeStorage:       closeStorage,
 	}
 }
 
@@ -45,10 +73,11 @@ func (s *App) MustRun() {
 }
 
 func (a *App) Run() error {
+	log := logger.GetLoggerFromCtx(a.ctx)
 	errCh := make(chan error, 1)
 	a.wg.Add(1)
 	go func() {
-		logger.GetLoggerFromCtx(a.ctx).Info("Server started on address", zap.Any("address", a.cfg.Host+":"+a.cfg.Port))
+		log.Info("Server started on address", zap.Any("address", a.cfg.Host+":"+a.cfg.Port))
 		defer a.wg.Done()
 		if err := a.SubscriptionServer.Run(); err != nil {
 			errCh <- err
@@ -57,13 +86,35 @@ func (a *App) Run() error {
 	}()
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case sig := <-sigCh:
+			log.Info("shutdown signal received", zap.String("signal", sig.String()))
+			a.cancel()
+		case <-a.ctx.Done():
+		}
+	}()
+
+	<-a.ctx.Done()
+	var runErr error
 	select {
-	case err := <-errCh:
-		logger.GetLoggerFromCtx(a.ctx).Error("error running app", zap.Error(err))
-		return err
-	case <-a.ctx.Done():
-		logger.GetLoggerFromCtx(a.ctx).Info("context done")
+	case runErr = <-errCh:
+		log.Error("error running app", zap.Error(runErr))
+	default:
+	}
+
+	log.Info("shutting down server", zap.Duration("timeout", a.cfg.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(a.ctx), a.cfg.ShutdownTimeout)
+	defer cancel()
+	if err := a.SubscriptionServer.Shutdown(shutdownCtx); err != nil {
+		log.Error("error shutting down server", zap.Error(err))
+		runErr = errors.Join(runErr, err)
 	}
+	a.wg.Wait()
+	log.Info("server stopped")
 
-	return nil
+	a.closeStorage()
+	log.Info("storage closed")
+	return runErr
 }
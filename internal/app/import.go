@@ -0,0 +1,66 @@
+package app
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/service"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"os"
+)
+
+const (
+	importUsage = "usage: import [--dry-run] FILE, - reads the CSV from stdin"
+	// importActor is the audit log actor of the import command.
+	importActor = "import"
+)
+
+// Import runs the "import" subcommand: it creates subscriptions from a CSV
+// file like POST /api/v2/subscriptions/import and prints the report.
+func Import(cfg *config.Config, ctx context.Context, args []string) error {
+	dryRun := len(args) > 0 && args[0] == "--dry-run"
+	if dryRun {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return errors.New(importUsage)
+	}
+	if cfg.Storage != config.StoragePostgres {
+		return fmt.Errorf("import requires %q storage, got %q", config.StoragePostgres, cfg.Storage)
+	}
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	db, err := postgres.New(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	srv := service.NewSubscriptionService(repository.NewSubscriptionRepository(db, ctx), cfg, ctx)
+	report, err := srv.ImportSubscriptions(r, dryRun, &models.AuditMeta{Actor: importActor})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	logger.GetLoggerFromCtx(ctx).Info("subscriptions imported", zap.Bool("dry_run", dryRun),
+		zap.Int("rows", report.Rows), zap.Int("imported", report.Imported), zap.Int("failed", report.Failed))
+	return nil
+}
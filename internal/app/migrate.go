@@ -0,0 +1,109 @@
+package app
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/migrations"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/migrator"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+const migrateUsage = "usage: migrate up | migrate down N | migrate status"
+
+// MigrateUp applies all pending migrations embedded into the binary.
+func MigrateUp(cfg *config.Config, ctx context.Context) error {
+	m, err := migrator.New(migrations.FS, cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return migrateUp(m, ctx)
+}
+
+// Migrate runs the "migrate" subcommand with the arguments that follow it.
+func Migrate(cfg *config.Config, ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	steps := 0
+	switch args[0] {
+	case "up", "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	case "down":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		var err error
+		steps, err = strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid number of steps %q: %w", args[1], err)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	m, err := migrator.New(migrations.FS, cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		return migrateUp(m, ctx)
+	case "down":
+		if err := m.Down(steps); err != nil {
+			return err
+		}
+		version, _, err := m.Version()
+		if err != nil {
+			return err
+		}
+		logger.GetLoggerFromCtx(ctx).Info("migrations rolled back", zap.Int("steps", steps), zap.Uint("version", version))
+		return nil
+	default:
+		return migrateStatus(m)
+	}
+}
+
+func migrateUp(m *migrator.Migrator, ctx context.Context) error {
+	before, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil {
+		return err
+	}
+	after, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	logger.GetLoggerFromCtx(ctx).Info("migrations applied", zap.Uint("from_version", before), zap.Uint("to_version", after))
+	return nil
+}
+
+func migrateStatus(m *migrator.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current version: %d (dirty: %t)\n", version, dirty)
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied"
+		}
+		fmt.Printf("%-8s %d %s\n", state, status.Version, status.Name)
+	}
+	return nil
+}
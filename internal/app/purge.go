@@ -0,0 +1,60 @@
+package app
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/service"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	purgeUsage = "usage: purge [retention], e.g. purge 720h"
+	// purgeActor is the audit log actor of the purge command.
+	purgeActor = "purge"
+)
+
+// Purge runs the "purge" subcommand: it hard-deletes subscriptions deleted
+// more than the retention ago, PURGE_RETENTION unless given as an argument,
+// and expired idempotency keys.
+func Purge(cfg *config.Config, ctx context.Context, args []string) error {
+	retention := cfg.PurgeRetention
+	switch len(args) {
+	case 0:
+	case 1:
+		var err error
+		retention, err = time.ParseDuration(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid retention %q: %w", args[0], err)
+		}
+	default:
+		return errors.New(purgeUsage)
+	}
+	if cfg.Storage != config.StoragePostgres {
+		return fmt.Errorf("purge requires %q storage, got %q", config.StoragePostgres, cfg.Storage)
+	}
+
+	db, err := postgres.New(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	srv := service.NewSubscriptionService(repository.NewSubscriptionRepository(db, ctx), cfg, ctx)
+	purged, err := srv.Purge(retention, &models.AuditMeta{Actor: purgeActor})
+	if err != nil {
+		return err
+	}
+	logger.GetLoggerFromCtx(ctx).Info("deleted subscriptions purged", zap.Int64("purged", purged), zap.Duration("retention", retention))
+	expired, err := srv.PurgeIdempotencyKeys()
+	if err != nil {
+		return err
+	}
+	logger.GetLoggerFromCtx(ctx).Info("expired idempotency keys purged", zap.Int64("purged", expired))
+	return nil
+}
@@ -4,14 +4,36 @@ import (
 	"TestEffectiveMobile/pkg/postgres"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
+	"time"
 )
 
 type Config struct {
 	Postgres postgres.Config `yaml:"Postgres"`
 	Port     string          `yaml:"port" env-default:"4047"`
 	Host     string          `yaml:"host" env-default:"0.0.0.0"`
+	// Storage selects the subscription repository backend: "postgres" or "memory".
+	Storage string `yaml:"storage" env:"STORAGE" env-default:"postgres"`
+	// AutoMigrate applies pending migrations on startup.
+	AutoMigrate bool `yaml:"auto_migrate" env:"AUTO_MIGRATE" env-default:"false"`
+	// ShutdownTimeout bounds how long in-flight requests may take to finish on shutdown.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"10s"`
+	// AdminToken is the bearer token of the /admin routes, they are disabled when it is empty.
+	AdminToken string `yaml:"admin_token" env:"ADMIN_TOKEN"`
+	// AdminOpen leaves the /admin routes open without a token, for local runs only.
+	AdminOpen bool `yaml:"admin_open" env:"ADMIN_OPEN" env-default:"false"`
+	// RequireIfMatch rejects updates and deletes without an If-Match header.
+	RequireIfMatch bool `yaml:"require_if_match" env:"REQUIRE_IF_MATCH" env-default:"false"`
+	// PurgeRetention is how long deleted subscriptions are kept before the purge command removes them.
+	PurgeRetention time.Duration `yaml:"purge_retention" env:"PURGE_RETENTION" env-default:"720h"`
+	// IdempotencyTTL is how long the response to a request with an Idempotency-Key is kept for its retries.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
 }
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
 func NewConfig() (*Config, error) {
 	_ = godotenv.Load(".env")
 
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions of AuditEntry.
+const (
+	AuditCreate      = "create"
+	AuditUpdate      = "update"
+	AuditDelete      = "delete"
+	AuditPriceChange = "price_change"
+	AuditRestore     = "restore"
+	AuditPurge       = "purge"
+)
+
+// AuditMeta tells who mutates a subscription and in which request.
+type AuditMeta struct {
+	Actor     string
+	RequestId string
+}
+
+// AuditEntry is a single mutation of a subscription. Before and After are
+// snapshots of the subscription, After of a price change is the change.
+type AuditEntry struct {
+	Id             int64           `json:"id" example:"1"`
+	SubscriptionId string          `json:"subscription_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Action         string          `json:"action" example:"update" enums:"create,update,delete,price_change,restore,purge"`
+	Actor          string          `json:"actor" example:"billing-service"`
+	RequestId      string          `json:"request_id" example:"7f9c0b1e-3a5d-4c2e-9b8a-1d2e3f4a5b6c"`
+	ChangedAt      time.Time       `json:"changed_at" example:"2025-09-20T10:00:00Z"`
+	Before         json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After          json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+}
+
+type HistoryResponse struct {
+	Entries []*AuditEntry `json:"entries"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import "TestEffectiveMobile/pkg/money"
+
+// Modes of a bulk request: an atomic batch is applied all-or-nothing, a best
+// effort batch applies every item that succeeds.
+const (
+	BulkAtomic     = "atomic"
+	BulkBestEffort = "best_effort"
+)
+
+type BulkCreateRequest struct {
+	Mode  string               `json:"mode" enums:"atomic,best_effort" example:"atomic"`
+	Items []CreateSubscription `json:"items"`
+}
+
+type BulkUpdateRequest struct {
+	Mode  string           `json:"mode" enums:"atomic,best_effort" example:"atomic"`
+	Items []BulkUpdateItem `json:"items"`
+}
+
+// BulkUpdateItem patches the subscription Id, Version works as the If-Match
+// header of a single update.
+type BulkUpdateItem struct {
+	Id            string       `json:"id"`
+	Version       *int64       `json:"version,omitempty" example:"1"`
+	ServiceName   *string      `json:"service_name,omitempty"`
+	Price         *money.Money `json:"price,omitempty"`
+	BillingPeriod *string      `json:"billing_period,omitempty"`
+	StartDate     *string      `json:"start_date,omitempty"`
+	EndDate       *string      `json:"end_date,omitempty"`
+}
+
+type BulkDeleteRequest struct {
+	Mode  string           `json:"mode" enums:"atomic,best_effort" example:"atomic"`
+	Items []BulkDeleteItem `json:"items"`
+}
+
+// BulkDeleteItem deletes the subscription Id, Version works as the If-Match
+// header of a single delete.
+type BulkDeleteItem struct {
+	Id      string `json:"id"`
+	Version *int64 `json:"version,omitempty" example:"1"`
+}
+
+// BulkResponse reports the outcome of every item of a bulk request in the
+// order of the request.
+type BulkResponse struct {
+	Mode      string           `json:"mode" example:"atomic"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// BulkItemResult holds the status the item would have got as a single
+// request and either the resulting subscription or the problem.
+type BulkItemResult struct {
+	Index        int           `json:"index"`
+	Id           string        `json:"id,omitempty"`
+	Status       int           `json:"status" example:"201"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	Error        *BadResponse  `json:"error,omitempty"`
+}
+
+// Subscription converts the item of a bulk create to the subscription to create.
+func (c *CreateSubscription) Subscription() *Subscription {
+	return &Subscription{
+		ServiceName:   c.ServiceName,
+		Price:         c.Price,
+		BillingPeriod: c.BillingPeriod,
+		UserId:        c.UserId,
+		StartDate:     c.StartDate,
+		EndDate:       c.EndDate,
+	}
+}
+
+// versionMatch converts the version of a bulk item to the precondition of
+// its update or delete, nil when the item has no version.
+func versionMatch(version *int64) *VersionMatch {
+	if version == nil {
+		return nil
+	}
+	return &VersionMatch{Versions: []int64{*version}}
+}
+
+func (i *BulkUpdateItem) Match() *VersionMatch {
+	return versionMatch(i.Version)
+}
+
+// Update returns the fields of the item to patch.
+func (i *BulkUpdateItem) Update() *UpdateSubscription {
+	return &UpdateSubscription{
+		ServiceName:   i.ServiceName,
+		Price:         i.Price,
+		BillingPeriod: i.BillingPeriod,
+		StartDate:     i.StartDate,
+		EndDate:       i.EndDate,
+	}
+}
+
+func (i *BulkDeleteItem) Match() *VersionMatch {
+	return versionMatch(i.Version)
+}
@@ -0,0 +1,14 @@
+package models
+
+// ExchangeRate says that one unit of Base costs Rate units of Quote starting
+// from the EffectiveFrom month (MM-YYYY) until the next rate of the pair.
+type ExchangeRate struct {
+	Base          string  `json:"base" example:"USD"`
+	Quote         string  `json:"quote" example:"RUB"`
+	Rate          float64 `json:"rate" example:"92.5"`
+	EffectiveFrom string  `json:"effective_from" example:"01-2025"`
+}
+
+type ExchangeRatesResponse struct {
+	Rates []*ExchangeRate `json:"rates"`
+}
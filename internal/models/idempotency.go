@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is a request made with an Idempotency-Key header and the
+// response to replay for its retries. Status is 0 while the first request is
+// still in progress.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	Status      int
+	Headers     map[string]string
+	Body        []byte
+	ExpiresAt   time.Time
+}
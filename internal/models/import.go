@@ -0,0 +1,25 @@
+package models
+
+import "TestEffectiveMobile/pkg/suberrors"
+
+// ImportOptions are the query parameters of a CSV import.
+type ImportOptions struct {
+	DryRun bool `form:"dry_run"`
+}
+
+// ImportReport is the outcome of a CSV import of subscriptions, rows with
+// errors are skipped and the valid ones are created unless DryRun.
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Rows     int              `json:"rows" example:"3"`
+	Valid    int              `json:"valid" example:"2"`
+	Imported int              `json:"imported" example:"2"`
+	Failed   int              `json:"failed" example:"1"`
+	Errors   []ImportRowError `json:"errors,omitempty"`
+}
+
+// ImportRowError lists the invalid fields of the CSV row on Line.
+type ImportRowError struct {
+	Line   int                    `json:"line" example:"3"`
+	Errors []suberrors.FieldError `json:"errors"`
+}
@@ -2,4 +2,25 @@ package models
 
 type ListSubscriptionsResponse struct {
 	Subscriptions []*Subscription `json:"subscriptions"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+}
+
+// SubscriptionFilter selects subscriptions across users. Prices are in minor
+// units, dates are MM-YYYY, Sort is one of price, start_date or service_name, prefixed with "-" for
+// descending order.
+type SubscriptionFilter struct {
+	UserId        string `form:"user_id"`
+	ServiceName   string `form:"service_name"`
+	MinPrice      *int64 `form:"min_price"`
+	MaxPrice      *int64 `form:"max_price"`
+	Currency      string `form:"currency"`
+	BillingPeriod string `form:"billing_period"`
+	ActiveAt      string `form:"active_at"`
+	StartFrom     string `form:"start_from"`
+	StartTo       string `form:"start_to"`
+	EndFrom       string `form:"end_from"`
+	EndTo         string `form:"end_to"`
+	Sort          string `form:"sort"`
+	Limit         int    `form:"limit"`
+	Cursor        string `form:"cursor"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "slices"
+
+// VersionMatch is the If-Match precondition of a write: the versions of the
+// subscription the client has seen, or any version for "*". A nil
+// *VersionMatch means the client sent no precondition.
+type VersionMatch struct {
+	Any      bool
+	Versions []int64
+}
+
+// Matches reports whether a subscription at version satisfies the
+// precondition, no precondition is always satisfied.
+func (m *VersionMatch) Matches(version int64) bool {
+	return m == nil || m.Any || slices.Contains(m.Versions, version)
+}
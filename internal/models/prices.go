@@ -0,0 +1,17 @@
+package models
+
+import "TestEffectiveMobile/pkg/money"
+
+// PriceChange is the price of a subscription from the EffectiveFrom month
+// (MM-YYYY) until the next change, a Price without currency keeps the
+// current one.
+type PriceChange struct {
+	Price         money.Money `json:"price"`
+	EffectiveFrom string      `json:"effective_from" example:"01-2026"`
+}
+
+// PriceHistoryResponse lists the prices of a subscription starting with the
+// one it was created with.
+type PriceHistoryResponse struct {
+	Prices []*PriceChange `json:"prices"`
+}
@@ -0,0 +1,74 @@
+package models
+
+// Cost modes of sums and reports: a charged subscription costs its price in
+// every month it is billed in, an amortized one costs its price spread evenly
+// over the months of its billing period.
+const (
+	CostCharged   = "charged"
+	CostAmortized = "amortized"
+)
+
+// ReportFilter selects the subscriptions and the MM-YYYY window a sum or a
+// report is calculated for, empty UserId and ServiceName match everything.
+// Costs are converted into Currency if it is set and calculated in Mode,
+// CostCharged by default.
+type ReportFilter struct {
+	UserId      string `form:"user_id"`
+	ServiceName string `form:"service_name"`
+	Currency    string `form:"currency"`
+	Mode        string `form:"mode"`
+	StartDate   string `form:"start_date"`
+	EndDate     string `form:"end_date"`
+}
+
+// MonthlyReport holds totals in minor units of Currency.
+type MonthlyReport struct {
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	Currency  string        `json:"currency"`
+	Total     int64         `json:"total"`
+	Months    []*MonthTotal `json:"months"`
+}
+
+type MonthTotal struct {
+	Month    string          `json:"month"`
+	Total    int64           `json:"total"`
+	Services []*ServiceTotal `json:"services"`
+}
+
+type ServiceTotal struct {
+	ServiceName string `json:"service_name"`
+	Total       int64  `json:"total"`
+}
+
+// AggregateFilter groups the subscriptions selected by ReportFilter by
+// GroupBy, one of service_name, user_id or month. OrderBy is one of key,
+// count, sum, avg, min or max, prefixed with "-" for descending order, a
+// positive Limit keeps only the first groups.
+type AggregateFilter struct {
+	ReportFilter
+	GroupBy string `form:"group_by"`
+	OrderBy string `form:"order_by"`
+	Limit   int    `form:"limit"`
+}
+
+// AggregateReport holds costs in minor units of Currency.
+type AggregateReport struct {
+	StartDate string            `json:"start_date"`
+	EndDate   string            `json:"end_date"`
+	Currency  string            `json:"currency"`
+	GroupBy   string            `json:"group_by"`
+	Groups    []*AggregateGroup `json:"groups"`
+}
+
+// AggregateGroup describes what the subscriptions of a group cost within the
+// window: Count is the number of subscriptions, Avg, Min and Max are taken
+// over their costs.
+type AggregateGroup struct {
+	Key   string  `json:"key"`
+	Count int     `json:"count"`
+	Sum   int64   `json:"sum"`
+	Avg   float64 `json:"avg"`
+	Min   int64   `json:"min"`
+	Max   int64   `json:"max"`
+}
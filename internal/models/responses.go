@@ -1,13 +1,25 @@
 package models
 
+import "TestEffectiveMobile/pkg/suberrors"
+
 type GoodResponse struct {
 	Message string `json:"message"`
 }
 
+// BadResponse is an RFC 7807 problem document, served as application/problem+json.
 type BadResponse struct {
-	Error string `json:"error"`
+	Type      string                 `json:"type" example:"/problems/validation-error"`
+	Title     string                 `json:"title" example:"Validation failed"`
+	Status    int                    `json:"status" example:"400"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty" example:"/api/v1/create"`
+	RequestId string                 `json:"request_id,omitempty"`
+	Errors    []suberrors.FieldError `json:"errors,omitempty"`
 }
 
+// SumSubscriptionsResponse holds Sum in minor units of Currency.
 type SumSubscriptionsResponse struct {
-	Sum int `json:"sum"`
+	Sum      int64  `json:"sum"`
+	Currency string `json:"currency"`
+	Months   int    `json:"months"`
 }
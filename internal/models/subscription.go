@@ -1,18 +1,31 @@
 package models
 
+import "TestEffectiveMobile/pkg/money"
+
+// Billing periods of a subscription, its price is charged once per period.
+const (
+	BillingWeekly    = "weekly"
+	BillingMonthly   = "monthly"
+	BillingQuarterly = "quarterly"
+	BillingYearly    = "yearly"
+)
+
 type Subscription struct {
-	ServiceName string `json:"service_name"`
-	Price       int    `json:"price"`
-	Id          string `json:"id"`
-	UserId      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
+	ServiceName   string      `json:"service_name"`
+	Price         money.Money `json:"price"`
+	BillingPeriod string      `json:"billing_period" example:"monthly"`
+	Id            string      `json:"id"`
+	UserId        string      `json:"user_id"`
+	StartDate     string      `json:"start_date"`
+	EndDate       string      `json:"end_date,omitempty"`
+	Version       int64       `json:"version" example:"1"`
 }
 
 type CreateSubscription struct {
-	ServiceName string `json:"service_name"`
-	Price       int    `json:"price"`
-	UserId      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
+	ServiceName   string      `json:"service_name"`
+	Price         money.Money `json:"price"`
+	BillingPeriod string      `json:"billing_period,omitempty" example:"monthly"`
+	UserId        string      `json:"user_id"`
+	StartDate     string      `json:"start_date"`
+	EndDate       string      `json:"end_date,omitempty"`
 }
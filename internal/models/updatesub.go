@@ -1,8 +1,14 @@
 package models
 
+import "TestEffectiveMobile/pkg/money"
+
+// UpdateSubscription holds the fields to change; a nil field is left as is.
+// An empty EndDate removes the end date and makes the subscription open-ended,
+// a Price without currency keeps the current one.
 type UpdateSubscription struct {
-	ServiceName string `json:"service_name"`
-	Price       int    `json:"price"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
+	ServiceName   *string      `json:"service_name"`
+	Price         *money.Money `json:"price"`
+	BillingPeriod *string      `json:"billing_period"`
+	StartDate     *string      `json:"start_date"`
+	EndDate       *string      `json:"end_date"`
 }
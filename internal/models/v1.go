@@ -0,0 +1,26 @@
+package models
+
+// SubscriptionV1 is a subscription in the shape of the deprecated v1 API:
+// the price is a bare integer in major units of its currency.
+type SubscriptionV1 struct {
+	ServiceName   string `json:"service_name"`
+	Price         int64  `json:"price" example:"399"`
+	Currency      string `json:"currency" example:"RUB"`
+	BillingPeriod string `json:"billing_period" example:"monthly"`
+	Id            string `json:"id"`
+	UserId        string `json:"user_id"`
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date,omitempty"`
+	Version       int64  `json:"version" example:"1"`
+}
+
+type ListSubscriptionsV1Response struct {
+	Subscriptions []*SubscriptionV1 `json:"subscriptions"`
+}
+
+// SumSubscriptionsV1Response holds Sum in major units of Currency.
+type SumSubscriptionsV1Response struct {
+	Sum      int64  `json:"sum"`
+	Currency string `json:"currency"`
+	Months   int    `json:"months"`
+}
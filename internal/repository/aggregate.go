@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+const defaultAggregateOrder = "-sum"
+
+// aggregateGroupings maps the group_by values of AggregateSubscriptions to
+// the key of a charge and an ordinal the keys are sorted by.
+var aggregateGroupings = map[string]struct {
+	key     string
+	ordinal string
+}{
+	"service_name": {key: "service_name", ordinal: "service_name"},
+	"user_id":      {key: "user_id", ordinal: "user_id"},
+	"month":        {key: "to_char(month, 'MM-YYYY')", ordinal: "to_char(month, 'YYYY-MM')"},
+}
+
+// aggregateOrders are the columns groups can be ordered by in AggregateSubscriptions.
+var aggregateOrders = map[string]bool{
+	"key":   true,
+	"count": true,
+	"sum":   true,
+	"avg":   true,
+	"min":   true,
+	"max":   true,
+}
+
+// IsValidGroupBy reports whether subscriptions can be aggregated by groupBy.
+func IsValidGroupBy(groupBy string) bool {
+	_, ok := aggregateGroupings[groupBy]
+	return ok
+}
+
+// ParseAggregateOrder splits an order_by parameter such as "-sum" into the
+// field and the direction, groups are ordered by descending sum by default.
+func ParseAggregateOrder(orderBy string) (field string, desc bool, ok bool) {
+	if orderBy == "" {
+		orderBy = defaultAggregateOrder
+	}
+	desc = strings.HasPrefix(orderBy, "-")
+	field = strings.TrimPrefix(orderBy, "-")
+	return field, desc, aggregateOrders[field]
+}
+
+// aggregateGroup is a group of AggregateSubscriptions with the ordinal its
+// key is sorted by.
+type aggregateGroup struct {
+	models.AggregateGroup
+	ordinal string
+}
+
+// groupKey mirrors aggregateGroupings for a single charge.
+func groupKey(groupBy string, charge charge) (key string, ordinal string) {
+	switch groupBy {
+	case "user_id":
+		return charge.sub.UserId, charge.sub.UserId
+	case "month":
+		return charge.month.Format("01-2006"), charge.month.Format("2006-01")
+	default:
+		return charge.sub.ServiceName, charge.sub.ServiceName
+	}
+}
+
+// aggregateCharges mirrors the query of SubscriptionRepository.AggregateSubscriptions:
+// charges are summed up per subscription within a group and the groups are
+// described by these costs.
+func aggregateCharges(filter *models.AggregateFilter, charges []charge) []*models.AggregateGroup {
+	type costKey struct {
+		key            string
+		subscriptionId string
+	}
+	costs := make(map[costKey]int64)
+	var keys []costKey
+	ordinals := make(map[string]string)
+	for _, charge := range charges {
+		key, ordinal := groupKey(filter.GroupBy, charge)
+		k := costKey{key: key, subscriptionId: charge.sub.Id}
+		if _, ok := costs[k]; !ok {
+			keys = append(keys, k)
+		}
+		costs[k] += charge.amount
+		ordinals[key] = ordinal
+	}
+
+	byKey := make(map[string]*aggregateGroup)
+	var groups []*aggregateGroup
+	for _, k := range keys {
+		cost := costs[k]
+		group, ok := byKey[k.key]
+		if !ok {
+			group = &aggregateGroup{
+				AggregateGroup: models.AggregateGroup{Key: k.key, Min: cost, Max: cost},
+				ordinal:        ordinals[k.key],
+			}
+			byKey[k.key] = group
+			groups = append(groups, group)
+		}
+		group.Count++
+		group.Sum += cost
+		group.Min = min(group.Min, cost)
+		group.Max = max(group.Max, cost)
+	}
+	for _, group := range groups {
+		group.Avg = math.Round(float64(group.Sum)/float64(group.Count)*100) / 100
+	}
+
+	field, desc, _ := ParseAggregateOrder(filter.OrderBy)
+	slices.SortFunc(groups, func(a, b *aggregateGroup) int {
+		var result int
+		switch field {
+		case "count":
+			result = cmp.Compare(a.Count, b.Count)
+		case "sum":
+			result = cmp.Compare(a.Sum, b.Sum)
+		case "avg":
+			result = cmp.Compare(a.Avg, b.Avg)
+		case "min":
+			result = cmp.Compare(a.Min, b.Min)
+		case "max":
+			result = cmp.Compare(a.Max, b.Max)
+		default:
+			result = strings.Compare(a.ordinal, b.ordinal)
+		}
+		if desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+		return strings.Compare(a.ordinal, b.ordinal)
+	})
+	if filter.Limit > 0 && len(groups) > filter.Limit {
+		groups = groups[:filter.Limit]
+	}
+	result := make([]*models.AggregateGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, &group.AggregateGroup)
+	}
+	return result
+}
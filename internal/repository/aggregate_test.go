@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAggregateChargesOrder(t *testing.T) {
+	charges := []charge{
+		{sub: models.Subscription{Id: "1", ServiceName: "Netflix", UserId: "alice"}, month: month(2025, time.December), amount: 100},
+		{sub: models.Subscription{Id: "1", ServiceName: "Netflix", UserId: "alice"}, month: month(2026, time.January), amount: 100},
+		{sub: models.Subscription{Id: "2", ServiceName: "Spotify", UserId: "bob"}, month: month(2026, time.January), amount: 300},
+		{sub: models.Subscription{Id: "3", ServiceName: "Apple", UserId: "bob"}, month: month(2026, time.February), amount: 50},
+		{sub: models.Subscription{Id: "4", ServiceName: "Apple", UserId: "carol"}, month: month(2026, time.February), amount: 150},
+	}
+	tests := []struct {
+		name    string
+		groupBy string
+		orderBy string
+		limit   int
+		want    []string
+	}{
+		// Apple and Netflix both sum up to 200 and are ordered by key
+		{name: "default is descending sum", groupBy: "service_name", want: []string{"Spotify", "Apple", "Netflix"}},
+		{name: "key ascending", groupBy: "service_name", orderBy: "key", want: []string{"Apple", "Netflix", "Spotify"}},
+		{name: "key descending", groupBy: "service_name", orderBy: "-key", want: []string{"Spotify", "Netflix", "Apple"}},
+		{name: "ties by key", groupBy: "service_name", orderBy: "-count", want: []string{"Apple", "Netflix", "Spotify"}},
+		{name: "min", groupBy: "service_name", orderBy: "min", want: []string{"Apple", "Netflix", "Spotify"}},
+		{name: "avg", groupBy: "user_id", orderBy: "-avg", want: []string{"alice", "bob", "carol"}},
+		{name: "months across years", groupBy: "month", orderBy: "key", want: []string{"12-2025", "01-2026", "02-2026"}},
+		{name: "months descending", groupBy: "month", orderBy: "-key", want: []string{"02-2026", "01-2026", "12-2025"}},
+		{name: "limit", groupBy: "service_name", limit: 2, want: []string{"Spotify", "Apple"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &models.AggregateFilter{GroupBy: tt.groupBy, OrderBy: tt.orderBy, Limit: tt.limit}
+			var got []string
+			for _, group := range aggregateCharges(filter, charges) {
+				got = append(got, group.Key)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("aggregateCharges(%q, %q) keys = %v, want %v", tt.groupBy, tt.orderBy, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateChargesStatistics(t *testing.T) {
+	charges := []charge{
+		{sub: models.Subscription{Id: "1", ServiceName: "Netflix"}, month: month(2025, time.January), amount: 100},
+		{sub: models.Subscription{Id: "1", ServiceName: "Netflix"}, month: month(2025, time.February), amount: 100},
+		{sub: models.Subscription{Id: "2", ServiceName: "Netflix"}, month: month(2025, time.February), amount: 101},
+	}
+	groups := aggregateCharges(&models.AggregateFilter{GroupBy: "service_name"}, charges)
+	want := models.AggregateGroup{Key: "Netflix", Count: 2, Sum: 301, Avg: 150.5, Min: 101, Max: 200}
+	if len(groups) != 1 || *groups[0] != want {
+		t.Fatalf("aggregateCharges = %+v, want %+v", groups, want)
+	}
+}
+
+func TestParseAggregateOrder(t *testing.T) {
+	tests := []struct {
+		orderBy string
+		field   string
+		desc    bool
+		ok      bool
+	}{
+		{orderBy: "", field: "sum", desc: true, ok: true},
+		{orderBy: "key", field: "key", ok: true},
+		{orderBy: "-avg", field: "avg", desc: true, ok: true},
+		{orderBy: "price", field: "price", ok: false},
+	}
+	for _, tt := range tests {
+		field, desc, ok := ParseAggregateOrder(tt.orderBy)
+		if field != tt.field || desc != tt.desc || ok != tt.ok {
+			t.Errorf("ParseAggregateOrder(%q) = %q, %v, %v, want %q, %v, %v", tt.orderBy, field, desc, ok, tt.field, tt.desc, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"slices"
+	"time"
+)
+
+// Every mutation of a subscription appends an entry to subscription_audit in
+// the transaction of the mutation itself, so that a change is never visible
+// without its entry. Entries outlive the subscription they describe.
+
+const insertAuditEntry = `
+        INSERT INTO subscription_audit (subscription_id, action, actor, request_id, before, after)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+
+// audit appends an entry for subscription id to subscription_audit within tx,
+// before and after are JSON snapshots or nil.
+func (s *SubscriptionRepository) audit(tx pgx.Tx, id string, action string, meta *models.AuditMeta, before []byte, after []byte) error {
+	_, err := tx.Exec(s.ctx, insertAuditEntry, id, action, meta.Actor, meta.RequestId, before, after)
+	if err != nil {
+		return fmt.Errorf("error writing audit entry: %w", err)
+	}
+	return nil
+}
+
+// lockSubscription locks the row of id until the end of tx and returns the
+// subscription as reads see it.
+func (s *SubscriptionRepository) lockSubscription(tx pgx.Tx, id string) (*models.Subscription, error) {
+	if _, err := tx.Exec(s.ctx, "SELECT 1 FROM subscriptions WHERE id = $1 FOR UPDATE", id); err != nil {
+		return nil, err
+	}
+	return scanSubscription(tx.QueryRow(s.ctx, selectSubscription, id))
+}
+
+func (s *SubscriptionRepository) History(id string) ([]*models.AuditEntry, error) {
+	if !isValidId(id) {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	rows, err := s.db.Query(s.ctx, `
+        SELECT id, subscription_id, action, actor, request_id, changed_at, before, after
+        FROM subscription_audit
+        WHERE subscription_id = $1
+        ORDER BY id`,
+		id)
+	if err != nil {
+		return nil, fmt.Errorf("error reading subscription history: %w", err)
+	}
+	defer rows.Close()
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after []byte
+		err := rows.Scan(&entry.Id, &entry.SubscriptionId, &entry.Action, &entry.Actor, &entry.RequestId,
+			&entry.ChangedAt, &before, &after)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	return entries, nil
+}
+
+// snapshot marshals v for the before and after columns, a nil subscription
+// has no snapshot.
+func snapshot[T any](v *T) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling audit snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// audit mirrors SubscriptionRepository.audit, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) audit(id string, action string, meta *models.AuditMeta, before []byte, after []byte) {
+	m.auditLog = append(m.auditLog, models.AuditEntry{
+		Id:             int64(len(m.auditLog) + 1),
+		SubscriptionId: id,
+		Action:         action,
+		Actor:          meta.Actor,
+		RequestId:      meta.RequestId,
+		ChangedAt:      time.Now().UTC(),
+		Before:         before,
+		After:          after,
+	})
+}
+
+func (m *MemorySubscriptionRepository) History(id string) ([]*models.AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var entries []*models.AuditEntry
+	for _, entry := range m.auditLog {
+		if entry.SubscriptionId == id {
+			entry.Before, entry.After = slices.Clone(entry.Before), slices.Clone(entry.After)
+			entries = append(entries, &entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	return entries, nil
+}
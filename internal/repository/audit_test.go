@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMemoryHistory(t *testing.T) {
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 1000, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"})
+	name := "Netflix Premium"
+	if _, err := repo.Update("1", &models.UpdateSubscription{ServiceName: &name}, nil, &models.AuditMeta{Actor: "billing", RequestId: "req-2"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	// a rejected update leaves no trace
+	negative := money.Money{Amount: -1, Currency: "RUB"}
+	if _, err := repo.Update("1", &models.UpdateSubscription{Price: &negative}, nil, testMeta); !errors.Is(err, suberrors.ErrConstraintViolation) {
+		t.Fatalf("Update with a negative price error = %v, want ErrConstraintViolation", err)
+	}
+	change := &models.PriceChange{Price: money.Money{Amount: 2000, Currency: "RUB"}, EffectiveFrom: "06-2025"}
+	if err := repo.SchedulePriceChange("1", change, testMeta); err != nil {
+		t.Fatalf("SchedulePriceChange: %v", err)
+	}
+	if err := repo.Delete("1", nil, &models.AuditMeta{Actor: "support", RequestId: "req-4"}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	entries, err := repo.History("1")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	var actions, actors []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		actors = append(actors, entry.Actor+"/"+entry.RequestId)
+		if entry.SubscriptionId != "1" || entry.ChangedAt.IsZero() {
+			t.Errorf("entry = %+v", entry)
+		}
+	}
+	if want := []string{models.AuditCreate, models.AuditUpdate, models.AuditPriceChange, models.AuditDelete}; !slices.Equal(actions, want) {
+		t.Fatalf("History actions = %v, want %v", actions, want)
+	}
+	if want := []string{"test/test-request", "billing/req-2", "test/test-request", "support/req-4"}; !slices.Equal(actors, want) {
+		t.Errorf("History actors = %v, want %v", actors, want)
+	}
+
+	snapshot := func(data json.RawMessage) string {
+		if data == nil {
+			return ""
+		}
+		var sub models.Subscription
+		if err := json.Unmarshal(data, &sub); err != nil {
+			t.Fatalf("decoding snapshot %s: %v", data, err)
+		}
+		return sub.ServiceName
+	}
+	snapshots := []string{
+		snapshot(entries[0].Before), snapshot(entries[0].After),
+		snapshot(entries[1].Before), snapshot(entries[1].After),
+		snapshot(entries[3].Before), snapshot(entries[3].After),
+	}
+	if want := []string{"", "Netflix", "Netflix", "Netflix Premium", "Netflix Premium", ""}; !slices.Equal(snapshots, want) {
+		t.Errorf("History snapshots = %q, want %q", snapshots, want)
+	}
+	var after models.PriceChange
+	if err := json.Unmarshal(entries[2].After, &after); err != nil || after != *change {
+		t.Errorf("price change entry after = %s, %v, want %+v", entries[2].After, err, *change)
+	}
+
+	if _, err := repo.History("2"); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("History of an unknown subscription error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+}
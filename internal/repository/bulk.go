@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"maps"
+	"slices"
+)
+
+// A bulk request runs in one transaction. In atomic mode the first failed
+// item rolls back the whole batch and every other item is reported as
+// aborted. In best effort mode every item runs in its own savepoint, so a
+// failed item rolls back alone and the others are committed.
+
+func (s *SubscriptionRepository) BulkCreate(subs []*models.Subscription, atomic bool, meta *models.AuditMeta) ([]error, error) {
+	args := make([][]any, len(subs))
+	afters := make([][]byte, len(subs))
+	for i, sub := range subs {
+		var err error
+		args[i], afters[i], err = insertArgs(sub)
+		if err != nil {
+			return nil, fmt.Errorf("error creating subscriptions: %w", err)
+		}
+	}
+	if !atomic {
+		errs, err := s.bulk(len(subs), false, func(tx pgx.Tx, i int) error {
+			if _, err := tx.Exec(s.ctx, insertSubscription, args[i]...); err != nil {
+				return fmt.Errorf("error creating subscription: %w", mapConstraintError(err))
+			}
+			return s.audit(tx, subs[i].Id, models.AuditCreate, meta, nil, afters[i])
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating subscriptions: %w", err)
+		}
+		return errs, nil
+	}
+
+	// atomic creates do not depend on each other, so the whole batch is
+	// sent to the server in one round trip
+	errs := make([]error, len(subs))
+	err := pgx.BeginFunc(s.ctx, s.db, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for i, sub := range subs {
+			batch.Queue(insertSubscription, args[i]...)
+			batch.Queue(insertAuditEntry, sub.Id, models.AuditCreate, meta.Actor, meta.RequestId, nil, afters[i])
+		}
+		results := tx.SendBatch(s.ctx, batch)
+		defer results.Close()
+		for i := range subs {
+			if _, err := results.Exec(); err != nil {
+				errs[i] = fmt.Errorf("error creating subscription: %w", mapConstraintError(err))
+				abortOthers(errs, i)
+				return errs[i]
+			}
+			if _, err := results.Exec(); err != nil {
+				errs[i] = fmt.Errorf("error writing audit entry: %w", err)
+				abortOthers(errs, i)
+				return errs[i]
+			}
+		}
+		return results.Close()
+	})
+	if err != nil && !hasFailed(errs) {
+		return nil, fmt.Errorf("error creating subscriptions: %w", err)
+	}
+	return errs, nil
+}
+
+func (s *SubscriptionRepository) BulkUpdate(items []*models.BulkUpdateItem, atomic bool, meta *models.AuditMeta) ([]*models.Subscription, []error, error) {
+	updated := make([]*models.Subscription, len(items))
+	errs, err := s.bulk(len(items), atomic, func(tx pgx.Tx, i int) error {
+		if !isValidId(items[i].Id) {
+			return suberrors.ErrIdSubscriptionNotFound
+		}
+		sub, err := s.update(tx, items[i].Id, items[i].Update(), items[i].Match(), meta)
+		if err != nil {
+			return updateError(err)
+		}
+		updated[i] = sub
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error updating subscriptions: %w", err)
+	}
+	if atomic && hasFailed(errs) {
+		// the batch was rolled back
+		clear(updated)
+	}
+	return updated, errs, nil
+}
+
+func (s *SubscriptionRepository) BulkDelete(items []*models.BulkDeleteItem, atomic bool, meta *models.AuditMeta) ([]error, error) {
+	errs, err := s.bulk(len(items), atomic, func(tx pgx.Tx, i int) error {
+		if !isValidId(items[i].Id) {
+			return suberrors.ErrIdSubscriptionNotFound
+		}
+		if err := s.delete(tx, items[i].Id, items[i].Match(), meta); err != nil {
+			return deleteError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error deleting subscriptions: %w", err)
+	}
+	return errs, nil
+}
+
+// bulk runs apply for each of n items within one transaction and returns the
+// error of every item, the error is returned only if the transaction failed.
+func (s *SubscriptionRepository) bulk(n int, atomic bool, apply func(tx pgx.Tx, i int) error) ([]error, error) {
+	errs := make([]error, n)
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(s.ctx) }()
+	for i := range n {
+		if atomic {
+			if errs[i] = apply(tx, i); errs[i] != nil {
+				abortOthers(errs, i)
+				return errs, nil
+			}
+			continue
+		}
+		// a transaction begun within tx is a savepoint
+		errs[i] = pgx.BeginFunc(s.ctx, tx, func(savepoint pgx.Tx) error {
+			return apply(savepoint, i)
+		})
+	}
+	if err := tx.Commit(s.ctx); err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// abortOthers marks every item but the failed one of an atomic batch as
+// rolled back with it.
+func abortOthers(errs []error, failed int) {
+	for i := range errs {
+		if i != failed {
+			errs[i] = suberrors.ErrBulkAborted
+		}
+	}
+}
+
+// hasFailed reports whether any item of a batch failed.
+func hasFailed(errs []error) bool {
+	return slices.ContainsFunc(errs, func(err error) bool {
+		return err != nil
+	})
+}
+
+func (m *MemorySubscriptionRepository) BulkCreate(subs []*models.Subscription, atomic bool, meta *models.AuditMeta) ([]error, error) {
+	return m.bulk(len(subs), atomic, func(i int) error {
+		return m.create(subs[i], meta)
+	}), nil
+}
+
+func (m *MemorySubscriptionRepository) BulkUpdate(items []*models.BulkUpdateItem, atomic bool, meta *models.AuditMeta) ([]*models.Subscription, []error, error) {
+	updated := make([]*models.Subscription, len(items))
+	errs := m.bulk(len(items), atomic, func(i int) error {
+		sub, err := m.update(items[i].Id, items[i].Update(), items[i].Match(), meta)
+		updated[i] = sub
+		return err
+	})
+	if atomic && hasFailed(errs) {
+		clear(updated)
+	}
+	return updated, errs, nil
+}
+
+func (m *MemorySubscriptionRepository) BulkDelete(items []*models.BulkDeleteItem, atomic bool, meta *models.AuditMeta) ([]error, error) {
+	return m.bulk(len(items), atomic, func(i int) error {
+		return m.delete(items[i].Id, items[i].Match(), meta)
+	}), nil
+}
+
+// bulk mirrors SubscriptionRepository.bulk, an atomic batch that fails is
+// rolled back to the state it started from.
+func (m *MemorySubscriptionRepository) bulk(n int, atomic bool, apply func(i int) error) []error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var state memoryState
+	if atomic {
+		state = m.saveState()
+	}
+	errs := make([]error, n)
+	for i := range n {
+		if errs[i] = apply(i); errs[i] != nil && atomic {
+			m.restoreState(state)
+			abortOthers(errs, i)
+			break
+		}
+	}
+	return errs
+}
+
+// memoryState is a copy of the subscriptions of the memory repository.
+type memoryState struct {
+	subscriptions map[string]models.Subscription
+	order         []string
+	prices        map[string][]priceChange
+	auditLen      int
+	deleted       map[string]deletedSubscription
+}
+
+// saveState copies the subscriptions, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) saveState() memoryState {
+	prices := make(map[string][]priceChange, len(m.prices))
+	for id, changes := range m.prices {
+		prices[id] = slices.Clone(changes)
+	}
+	return memoryState{
+		subscriptions: maps.Clone(m.subscriptions),
+		order:         slices.Clone(m.order),
+		prices:        prices,
+		auditLen:      len(m.auditLog),
+		deleted:       maps.Clone(m.deleted),
+	}
+}
+
+// restoreState brings back the subscriptions saved by saveState, m.mu must be
+// held by the caller.
+func (m *MemorySubscriptionRepository) restoreState(state memoryState) {
+	m.subscriptions = state.subscriptions
+	m.order = state.order
+	m.prices = state.prices
+	m.auditLog = m.auditLog[:state.auditLen]
+	m.deleted = state.deleted
+}
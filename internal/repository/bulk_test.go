@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"testing"
+)
+
+func bulkSubscriptions() []*models.Subscription {
+	return []*models.Subscription{
+		{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 300, Currency: "RUB"}, BillingPeriod: models.BillingMonthly, UserId: "user123", StartDate: "01-2025"},
+		{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, BillingPeriod: models.BillingMonthly, UserId: "user123", StartDate: "01-2025"},
+		{Id: "3", ServiceName: "Apple", Price: money.Money{Amount: 500, Currency: "RUB"}, BillingPeriod: models.BillingMonthly, UserId: "user123", StartDate: "01-2025"},
+	}
+}
+
+func TestMemoryBulkCreateAtomic(t *testing.T) {
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"})
+
+	// the second item collides with the existing subscription
+	errs, err := repo.BulkCreate(bulkSubscriptions(), true, testMeta)
+	if err != nil {
+		t.Fatalf("BulkCreate: %v", err)
+	}
+	if !errors.Is(errs[0], suberrors.ErrBulkAborted) || !errors.Is(errs[1], suberrors.ErrSubscriptionConflict) || !errors.Is(errs[2], suberrors.ErrBulkAborted) {
+		t.Errorf("BulkCreate errors = %v, want aborted, conflict, aborted", errs)
+	}
+	subs, err := repo.ListSubscriptions("user123")
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("ListSubscriptions after the rollback = %v, %v, want only the existing subscription", subs, err)
+	}
+	if _, err := repo.History("2"); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("History of a rolled back subscription error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+}
+
+func TestMemoryBulkCreateBestEffort(t *testing.T) {
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"})
+
+	errs, err := repo.BulkCreate(bulkSubscriptions(), false, testMeta)
+	if err != nil {
+		t.Fatalf("BulkCreate: %v", err)
+	}
+	if errs[0] != nil || !errors.Is(errs[1], suberrors.ErrSubscriptionConflict) || errs[2] != nil {
+		t.Errorf("BulkCreate errors = %v, want only the conflict", errs)
+	}
+	subs, err := repo.ListSubscriptions("user123")
+	if err != nil || len(subs) != 3 {
+		t.Errorf("ListSubscriptions = %v, %v, want 3 subscriptions", subs, err)
+	}
+}
+
+func TestMemoryBulkUpdateAtomic(t *testing.T) {
+	repo := newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025", Version: 1},
+		models.Subscription{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 300, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025", Version: 1},
+	)
+	name := "Renamed"
+	stale := int64(2)
+	items := []*models.BulkUpdateItem{
+		{Id: "1", ServiceName: &name},
+		{Id: "2", ServiceName: &name, Version: &stale},
+	}
+	updated, errs, err := repo.BulkUpdate(items, true, testMeta)
+	if err != nil {
+		t.Fatalf("BulkUpdate: %v", err)
+	}
+	if !errors.Is(errs[0], suberrors.ErrBulkAborted) || !errors.Is(errs[1], suberrors.ErrVersionConflict) {
+		t.Errorf("BulkUpdate errors = %v, want aborted, version conflict", errs)
+	}
+	if updated[0] != nil {
+		t.Errorf("BulkUpdate returned %+v of a rolled back update", updated[0])
+	}
+	if sub, err := repo.Read("1"); err != nil || sub.ServiceName != "Netflix" || sub.Version != 1 {
+		t.Errorf("Read after the rollback = %+v, %v, want the original subscription", sub, err)
+	}
+}
+
+func TestMemoryBulkDeleteBestEffort(t *testing.T) {
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"})
+
+	errs, err := repo.BulkDelete([]*models.BulkDeleteItem{{Id: "1"}, {Id: "2"}}, false, testMeta)
+	if err != nil {
+		t.Fatalf("BulkDelete: %v", err)
+	}
+	if errs[0] != nil || !errors.Is(errs[1], suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("BulkDelete errors = %v, want nil, not found", errs)
+	}
+	if _, err := repo.Read("1"); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("Read of a deleted subscription error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+}
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"TestEffectiveMobile/pkg/timeparser"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// billingMonths is the length of a billing period in months, weekly periods
+// are counted in days instead.
+var billingMonths = map[string]int{
+	models.BillingWeekly:    0,
+	models.BillingMonthly:   1,
+	models.BillingQuarterly: 3,
+	models.BillingYearly:    12,
+}
+
+// chargedShare is how many times a subscription is billed in month m: in
+// every month, quarter or year since its start month, or every seven days
+// since its start date.
+const chargedShare = `CASE s.billing_period
+                    WHEN 'quarterly' THEN CASE WHEN b.months_since_start % 3 = 0 THEN 1 ELSE 0 END
+                    WHEN 'yearly' THEN CASE WHEN b.months_since_start % 12 = 0 THEN 1 ELSE 0 END
+                    WHEN 'weekly' THEN (b.days_since_start + b.days_in_month - 1) / 7 - (b.days_since_start + 6) / 7 + 1
+                    ELSE 1
+                END`
+
+// amortizedShare is the part of the price of a subscription that falls on
+// month m when it is spread evenly over the billing period.
+const amortizedShare = `CASE s.billing_period
+                    WHEN 'quarterly' THEN 1 / 3.0
+                    WHEN 'yearly' THEN 1 / 12.0
+                    WHEN 'weekly' THEN b.days_in_month / 7.0
+                    ELSE 1
+                END`
+
+// chargesQuery returns a WITH clause that defines two relations shared by
+// sums and reports: "months", one row per month of the filter window, and
+// "charges", one row per month a matching subscription costs something in,
+// with the share of the price in effect in that month defined by filter.Mode.
+// If filter.Currency is set, every charge is converted into it with the
+// exchange rate effective in its month, or the inverse of the opposite rate;
+// amount is NULL when there is no such rate.
+// Keep it in sync with MemorySubscriptionRepository.charges.
+func chargesQuery(filter *models.ReportFilter) (string, []interface{}, error) {
+	stD, err := timeparser.ParseMonthYear(filter.StartDate)
+	if err != nil {
+		return "", nil, err
+	}
+	endD, err := timeparser.ParseMonthYear(filter.EndDate)
+	if err != nil {
+		return "", nil, err
+	}
+	args := []interface{}{stD, endD}
+	conditions := ""
+	if filter.ServiceName != "" {
+		args = append(args, filter.ServiceName)
+		conditions += fmt.Sprintf(" AND s.service_name = $%d", len(args))
+	}
+	if filter.UserId != "" {
+		args = append(args, filter.UserId)
+		conditions += fmt.Sprintf(" AND s.user_id = $%d", len(args))
+	}
+	share := chargedShare
+	if filter.Mode == models.CostAmortized {
+		share = amortizedShare
+	}
+	amount, currency, rates := "ROUND(pr.price * p.share::numeric)::bigint", "pr.currency", ""
+	if filter.Currency != "" {
+		args = append(args, filter.Currency)
+		target := fmt.Sprintf("$%d::char(3)", len(args))
+		amount = fmt.Sprintf("CASE WHEN pr.currency = %s THEN %s ELSE ROUND(pr.price * p.share::numeric * r.rate)::bigint END", target, amount)
+		currency = target
+		rates = fmt.Sprintf(`
+            LEFT JOIN LATERAL (
+                SELECT rate FROM (
+                    SELECT rate, effective_from, true AS direct FROM exchange_rates
+                    WHERE base_currency = pr.currency AND quote_currency = %[1]s AND effective_from <= m.month
+                    UNION ALL
+                    SELECT 1 / rate, effective_from, false FROM exchange_rates
+                    WHERE base_currency = %[1]s AND quote_currency = pr.currency AND effective_from <= m.month
+                ) pair_rates
+                ORDER BY effective_from DESC, direct DESC
+                LIMIT 1
+            ) r ON true`, target)
+	}
+	sql := `
+        WITH months AS (
+            SELECT generate_series($1::date, $2::date, interval '1 month')::date AS month
+        ),
+        charges AS (
+            SELECT s.id AS subscription_id, s.user_id, s.service_name, m.month,
+                ` + amount + ` AS amount, ` + currency + ` AS currency, pr.currency AS source_currency
+            FROM months m
+            JOIN subscriptions s
+                ON s.deleted_at IS NULL
+                AND s.start_date <= m.month
+                AND (s.end_date IS NULL OR s.end_date >= m.month)` + conditions + pricesInEffect + `
+            CROSS JOIN LATERAL (
+                SELECT (date_part('year', m.month) - date_part('year', s.start_date))::int * 12
+                        + (date_part('month', m.month) - date_part('month', s.start_date))::int AS months_since_start,
+                    m.month - s.start_date AS days_since_start,
+                    (m.month + interval '1 month')::date - m.month AS days_in_month
+            ) b
+            CROSS JOIN LATERAL (
+                SELECT ` + share + ` AS share
+            ) p` + rates + `
+            WHERE p.share > 0
+        )`
+	return sql, args, nil
+}
+
+// charge is a single month a subscription is billed in, amount is in
+// currency.
+type charge struct {
+	sub      models.Subscription
+	month    time.Time
+	amount   int64
+	currency string
+}
+
+// charges mirrors chargesQuery, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) charges(filter *models.ReportFilter) ([]charge, error) {
+	months, err := reportMonths(filter)
+	if err != nil {
+		return nil, err
+	}
+	var charges []charge
+	for _, id := range m.order {
+		sub := m.subscriptions[id]
+		if filter.UserId != "" && sub.UserId != filter.UserId {
+			continue
+		}
+		if filter.ServiceName != "" && sub.ServiceName != filter.ServiceName {
+			continue
+		}
+		stD, err := timeparser.ParseMonthYear(sub.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		endD, err := timeparser.ParseOptionalMonthYear(sub.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, month := range months {
+			if month.Before(stD) || (endD != nil && month.After(*endD)) {
+				continue
+			}
+			share := chargeShare(sub.BillingPeriod, filter.Mode, stD, month)
+			if share == 0 {
+				continue
+			}
+			price := m.priceAt(&sub, month)
+			c := charge{sub: sub, month: month, currency: price.Currency}
+			c.amount = int64(math.Round(float64(price.Amount) * share))
+			if filter.Currency != "" && c.currency != filter.Currency {
+				rate, ok := m.exchangeRate(c.currency, filter.Currency, month)
+				if !ok {
+					return nil, missingRateError(c.currency, filter.Currency, month)
+				}
+				c.amount = int64(math.Round(float64(price.Amount) * share * rate))
+				c.currency = filter.Currency
+			}
+			charges = append(charges, c)
+		}
+	}
+	return charges, nil
+}
+
+// chargeShare mirrors chargedShare and amortizedShare for a subscription
+// started in start, anything but a known longer period is billed monthly.
+func chargeShare(billingPeriod string, mode string, start time.Time, month time.Time) float64 {
+	daysInMonth := days(month.AddDate(0, 1, 0).Sub(month))
+	periodMonths := billingMonths[billingPeriod]
+	switch {
+	case billingPeriod == models.BillingWeekly && mode == models.CostAmortized:
+		return float64(daysInMonth) / 7
+	case billingPeriod == models.BillingWeekly:
+		daysSinceStart := days(month.Sub(start))
+		return float64((daysSinceStart+daysInMonth-1)/7 - (daysSinceStart+6)/7 + 1)
+	case periodMonths <= 1:
+		return 1
+	case mode == models.CostAmortized:
+		return 1 / float64(periodMonths)
+	}
+	monthsSinceStart := (month.Year()-start.Year())*12 + int(month.Month()-start.Month())
+	if monthsSinceStart%periodMonths != 0 {
+		return 0
+	}
+	return 1
+}
+
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
+
+// chargesCurrency returns the single currency of charges billed in currencies,
+// amounts in different currencies cannot be added up without conversion.
+func chargesCurrency(filter *models.ReportFilter, currencies []string) (string, error) {
+	switch len(currencies) {
+	case 0:
+		if filter.Currency != "" {
+			return filter.Currency, nil
+		}
+		return money.DefaultCurrency, nil
+	case 1:
+		return currencies[0], nil
+	}
+	return "", fmt.Errorf("%w: %s, pass currency to convert them",
+		suberrors.ErrMixedCurrencies, strings.Join(currencies, ", "))
+}
+
+// currencies lists the distinct currencies of charges in alphabetical order.
+func currencies(charges []charge) []string {
+	var codes []string
+	for _, charge := range charges {
+		if !slices.Contains(codes, charge.currency) {
+			codes = append(codes, charge.currency)
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+func missingRateError(from string, to string, month time.Time) error {
+	return fmt.Errorf("%w: no %s/%s rate effective in %s",
+		suberrors.ErrExchangeRateNotFound, from, to, month.Format("01-2006"))
+}
+
+// reportMonths lists the first days of every month of the filter window.
+func reportMonths(filter *models.ReportFilter) ([]time.Time, error) {
+	stD, err := timeparser.ParseMonthYear(filter.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	endD, err := timeparser.ParseMonthYear(filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	var months []time.Time
+	for month := stD; !month.After(endD); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+	return months, nil
+}
+
+// serviceMonthTotal is what a service costs in a single month.
+type serviceMonthTotal struct {
+	month       time.Time
+	serviceName string
+	total       int64
+}
+
+// monthlyReport builds a report with an entry for every month of the filter
+// window out of per-service totals ordered by service name.
+func monthlyReport(filter *models.ReportFilter, currency string, totals []serviceMonthTotal) (*models.MonthlyReport, error) {
+	months, err := reportMonths(filter)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.MonthlyReport{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Currency:  currency,
+		Months:    make([]*models.MonthTotal, 0, len(months)),
+	}
+	byMonth := make(map[string]*models.MonthTotal, len(months))
+	for _, month := range months {
+		monthTotal := &models.MonthTotal{
+			Month:    month.Format("01-2006"),
+			Services: []*models.ServiceTotal{},
+		}
+		byMonth[monthTotal.Month] = monthTotal
+		report.Months = append(report.Months, monthTotal)
+	}
+	for _, total := range totals {
+		monthTotal, ok := byMonth[total.month.Format("01-2006")]
+		if !ok {
+			continue
+		}
+		monthTotal.Total += total.total
+		monthTotal.Services = append(monthTotal.Services, &models.ServiceTotal{
+			ServiceName: total.serviceName,
+			Total:       total.total,
+		})
+		report.Total += total.total
+	}
+	return report, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestChargeShare(t *testing.T) {
+	start := month(2025, time.January)
+	tests := []struct {
+		name   string
+		period string
+		mode   string
+		month  time.Time
+		want   float64
+	}{
+		{name: "monthly", period: models.BillingMonthly, mode: models.CostCharged, month: month(2025, time.May), want: 1},
+		{name: "unknown period is monthly", period: "", mode: models.CostCharged, month: month(2025, time.May), want: 1},
+		{name: "monthly amortized", period: models.BillingMonthly, mode: models.CostAmortized, month: month(2025, time.May), want: 1},
+
+		// billed on January 1, 8, 15, 22 and 29
+		{name: "weekly start month", period: models.BillingWeekly, mode: models.CostCharged, month: month(2025, time.January), want: 5},
+		// billed on February 5, 12, 19 and 26
+		{name: "weekly february", period: models.BillingWeekly, mode: models.CostCharged, month: month(2025, time.February), want: 4},
+		// billed on March 5, 12, 19 and 26
+		{name: "weekly march", period: models.BillingWeekly, mode: models.CostCharged, month: month(2025, time.March), want: 4},
+		// billed on April 2, 9, 16, 23 and 30
+		{name: "weekly april", period: models.BillingWeekly, mode: models.CostCharged, month: month(2025, time.April), want: 5},
+		{name: "weekly amortized", period: models.BillingWeekly, mode: models.CostAmortized, month: month(2025, time.January), want: 31.0 / 7},
+		{name: "weekly amortized february", period: models.BillingWeekly, mode: models.CostAmortized, month: month(2025, time.February), want: 4},
+
+		{name: "quarterly start month", period: models.BillingQuarterly, mode: models.CostCharged, month: month(2025, time.January), want: 1},
+		{name: "quarterly between", period: models.BillingQuarterly, mode: models.CostCharged, month: month(2025, time.February), want: 0},
+		{name: "quarterly next quarter", period: models.BillingQuarterly, mode: models.CostCharged, month: month(2025, time.April), want: 1},
+		{name: "quarterly over the year", period: models.BillingQuarterly, mode: models.CostCharged, month: month(2026, time.January), want: 1},
+		{name: "quarterly amortized", period: models.BillingQuarterly, mode: models.CostAmortized, month: month(2025, time.February), want: 1.0 / 3},
+
+		{name: "yearly start month", period: models.BillingYearly, mode: models.CostCharged, month: month(2025, time.January), want: 1},
+		{name: "yearly between", period: models.BillingYearly, mode: models.CostCharged, month: month(2025, time.December), want: 0},
+		{name: "yearly next year", period: models.BillingYearly, mode: models.CostCharged, month: month(2026, time.January), want: 1},
+		{name: "yearly amortized", period: models.BillingYearly, mode: models.CostAmortized, month: month(2025, time.June), want: 1.0 / 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chargeShare(tt.period, tt.mode, start, tt.month)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("chargeShare(%q, %q, %s) = %v, want %v", tt.period, tt.mode, tt.month.Format("01-2006"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryCalculateSumBillingPeriods(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    string
+		amount    int64
+		startDate string
+		filter    models.ReportFilter
+		sum       int64
+		months    int
+	}{
+		{
+			name: "weekly charged", period: models.BillingWeekly, amount: 10000, startDate: "01-2025",
+			filter: models.ReportFilter{StartDate: "01-2025", EndDate: "03-2025", Mode: models.CostCharged},
+			sum:    (5 + 4 + 4) * 10000, months: 3,
+		},
+		{
+			name: "weekly amortized", period: models.BillingWeekly, amount: 10000, startDate: "01-2025",
+			filter: models.ReportFilter{StartDate: "01-2025", EndDate: "03-2025", Mode: models.CostAmortized},
+			sum:    44286 + 40000 + 44286, months: 3,
+		},
+		{
+			name: "quarterly charged", period: models.BillingQuarterly, amount: 30000, startDate: "01-2025",
+			filter: models.ReportFilter{StartDate: "01-2025", EndDate: "12-2025", Mode: models.CostCharged},
+			sum:    4 * 30000, months: 4,
+		},
+		{
+			name: "quarterly charged window after start", period: models.BillingQuarterly, amount: 30000, startDate: "01-2025",
+			filter: models.ReportFilter{StartDate: "02-2025", EndDate: "06-2025", Mode: models.CostCharged},
+			sum:    30000, months: 1,
+		},
+		{
+			name: "quarterly amortized", period: models.BillingQuarterly, amount: 30000, startDate: "01-2025",
+			filter: models.ReportFilter{StartDate: "02-2025", EndDate: "06-2025", Mode: models.CostAmortized},
+			sum:    5 * 10000, months: 5,
+		},
+		{
+			name: "yearly charged", period: models.BillingYearly, amount: 120000, startDate: "06-2025",
+			filter: models.ReportFilter{StartDate: "01-2025", EndDate: "12-2026", Mode: models.CostCharged},
+			sum:    2 * 120000, months: 2,
+		},
+		{
+			name: "yearly amortized", period: models.BillingYearly, amount: 120000, startDate: "06-2025",
+			filter: models.ReportFilter{StartDate: "01-2025", EndDate: "12-2026", Mode: models.CostAmortized},
+			sum:    19 * 10000, months: 19,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemorySubscriptionRepository()
+			sub := &models.Subscription{
+				Id:            "550e8400-e29b-41d4-a716-446655440000",
+				ServiceName:   "Netflix",
+				Price:         money.Money{Amount: tt.amount, Currency: "RUB"},
+				BillingPeriod: tt.period,
+				UserId:        "user123",
+				StartDate:     tt.startDate,
+			}
+			if err := repo.Create(sub, testMeta); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			got, err := repo.CalculateSumSubscriptions(&tt.filter)
+			if err != nil {
+				t.Fatalf("CalculateSumSubscriptions: %v", err)
+			}
+			if got.Sum != tt.sum || got.Months != tt.months || got.Currency != "RUB" {
+				t.Errorf("CalculateSumSubscriptions = %+v, want sum %d over %d months in RUB", got, tt.sum, tt.months)
+			}
+		})
+	}
+}
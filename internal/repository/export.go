@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"slices"
+	"strings"
+)
+
+// exportFetchSize is the number of rows fetched from the export cursor at a
+// time, so that an export holds only that many rows in memory.
+const exportFetchSize = 500
+
+// ExportSubscriptions passes every subscription matching filter to visit in
+// the sort order of the filter, its limit and cursor are ignored. The rows
+// are read from a server-side cursor in a read-only transaction, so the
+// export sees one snapshot of the table.
+func (s *SubscriptionRepository) ExportSubscriptions(filter *models.SubscriptionFilter, visit func(sub *models.Subscription) error) error {
+	field, desc, _ := ParseSort(filter.Sort)
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where, err := subscriptionConditions(filter, arg)
+	if err != nil {
+		return exportError(err)
+	}
+	sql := "DECLARE subscriptions_export NO SCROLL CURSOR FOR " +
+		"SELECT id, service_name, price, currency, billing_period, user_id, start_date, end_date, version FROM " + currentSubscriptions
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	sql += fmt.Sprintf(" ORDER BY %s %s, id %s", field, order, order)
+
+	tx, err := s.db.BeginTx(s.ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly, IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return exportError(err)
+	}
+	defer func() { _ = tx.Rollback(s.ctx) }()
+	if _, err := tx.Exec(s.ctx, sql, args...); err != nil {
+		return exportError(err)
+	}
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM subscriptions_export", exportFetchSize)
+	for {
+		rows, err := tx.Query(s.ctx, fetch)
+		if err != nil {
+			return exportError(err)
+		}
+		fetched := 0
+		for rows.Next() {
+			fetched++
+			sub, err := scanSubscription(rows)
+			if err == nil {
+				err = visit(sub)
+			}
+			if err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return exportError(err)
+		}
+		if fetched < exportFetchSize {
+			return nil
+		}
+	}
+}
+
+func exportError(err error) error {
+	return fmt.Errorf("error exporting subscriptions: %w", err)
+}
+
+func (m *MemorySubscriptionRepository) ExportSubscriptions(filter *models.SubscriptionFilter, visit func(sub *models.Subscription) error) error {
+	field, desc, _ := ParseSort(filter.Sort)
+	m.mu.RLock()
+	var subscriptions []*models.Subscription
+	for _, id := range m.order {
+		sub := m.current(m.subscriptions[id])
+		ok, err := matchesFilter(&sub, filter)
+		if err != nil {
+			m.mu.RUnlock()
+			return exportError(err)
+		}
+		if ok {
+			subscriptions = append(subscriptions, &sub)
+		}
+	}
+	// visit may block on a slow client, so it runs on the copies without
+	// holding the lock
+	m.mu.RUnlock()
+	slices.SortFunc(subscriptions, func(a, b *models.Subscription) int {
+		result := compareSortValues(field, cursorValue(a, field), cursorValue(b, field))
+		if result == 0 {
+			result = strings.Compare(a.Id, b.Id)
+		}
+		if desc {
+			return -result
+		}
+		return result
+	})
+	for _, sub := range subscriptions {
+		if err := visit(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"maps"
+	"slices"
+	"time"
+)
+
+// A request with an Idempotency-Key first reserves the key with a pending
+// record (status 0), its response is saved to the record once the request is
+// handled. An expired record is replaced by the next reservation and removed
+// by the purge command.
+
+func (s *SubscriptionRepository) ReserveIdempotencyKey(record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	var reserved bool
+	err := s.db.QueryRow(s.ctx, `
+        INSERT INTO idempotency_keys (scope, key, request_hash, expires_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (scope, key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash, status = 0, headers = NULL, body = NULL,
+            created_at = now(), expires_at = EXCLUDED.expires_at
+        WHERE idempotency_keys.expires_at <= now()
+        RETURNING true`,
+		record.Scope, record.Key, record.RequestHash, record.ExpiresAt).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("error reserving idempotency key: %w", err)
+	}
+	existing := models.IdempotencyRecord{Scope: record.Scope, Key: record.Key}
+	err = s.db.QueryRow(s.ctx, `
+        SELECT request_hash, status, headers, body, expires_at
+        FROM idempotency_keys
+        WHERE scope = $1 AND key = $2`,
+		record.Scope, record.Key).Scan(&existing.RequestHash, &existing.Status, &existing.Headers,
+		&existing.Body, &existing.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error reading idempotency key: %w", err)
+	}
+	return &existing, nil
+}
+
+func (s *SubscriptionRepository) SaveIdempotentResponse(record *models.IdempotencyRecord) error {
+	_, err := s.db.Exec(s.ctx,
+		"UPDATE idempotency_keys SET status = $3, headers = $4, body = $5 WHERE scope = $1 AND key = $2",
+		record.Scope, record.Key, record.Status, record.Headers, record.Body)
+	if err != nil {
+		return fmt.Errorf("error saving idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *SubscriptionRepository) ReleaseIdempotencyKey(scope string, key string) error {
+	_, err := s.db.Exec(s.ctx,
+		"DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status = 0",
+		scope, key)
+	if err != nil {
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *SubscriptionRepository) PurgeIdempotencyKeys() (int64, error) {
+	res, err := s.db.Exec(s.ctx, "DELETE FROM idempotency_keys WHERE expires_at <= now()")
+	if err != nil {
+		return 0, fmt.Errorf("error purging idempotency keys: %w", err)
+	}
+	return res.RowsAffected(), nil
+}
+
+// idempotencyKey is the primary key of the idempotency_keys table.
+type idempotencyKey struct {
+	scope string
+	key   string
+}
+
+func (m *MemorySubscriptionRepository) ReserveIdempotencyKey(record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pk := idempotencyKey{scope: record.Scope, key: record.Key}
+	if existing, ok := m.idempotency[pk]; ok && existing.ExpiresAt.After(time.Now()) {
+		existing.Headers, existing.Body = maps.Clone(existing.Headers), slices.Clone(existing.Body)
+		return &existing, nil
+	}
+	m.idempotency[pk] = models.IdempotencyRecord{
+		Scope:       record.Scope,
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		ExpiresAt:   record.ExpiresAt,
+	}
+	return nil, nil
+}
+
+func (m *MemorySubscriptionRepository) SaveIdempotentResponse(record *models.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pk := idempotencyKey{scope: record.Scope, key: record.Key}
+	existing, ok := m.idempotency[pk]
+	if !ok {
+		return nil
+	}
+	existing.Status = record.Status
+	existing.Headers, existing.Body = maps.Clone(record.Headers), slices.Clone(record.Body)
+	m.idempotency[pk] = existing
+	return nil
+}
+
+func (m *MemorySubscriptionRepository) ReleaseIdempotencyKey(scope string, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pk := idempotencyKey{scope: scope, key: key}
+	if existing, ok := m.idempotency[pk]; ok && existing.Status == 0 {
+		delete(m.idempotency, pk)
+	}
+	return nil
+}
+
+func (m *MemorySubscriptionRepository) PurgeIdempotencyKeys() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var purged int64
+	now := time.Now()
+	for pk, record := range m.idempotency {
+		if !record.ExpiresAt.After(now) {
+			delete(m.idempotency, pk)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,545 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/cursor"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"TestEffectiveMobile/pkg/timeparser"
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemorySubscriptionRepository keeps subscriptions in process memory.
+// It is meant for local demos and tests where Postgres is not available.
+type MemorySubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]models.Subscription
+	order         []string
+	rates         []exchangeRate
+	prices        map[string][]priceChange
+	auditLog      []models.AuditEntry
+	deleted       map[string]deletedSubscription
+	idempotency   map[idempotencyKey]models.IdempotencyRecord
+}
+
+// exchangeRate is a row of the exchange_rates table.
+type exchangeRate struct {
+	base          string
+	quote         string
+	rate          float64
+	effectiveFrom time.Time
+}
+
+func NewMemorySubscriptionRepository() *MemorySubscriptionRepository {
+	return &MemorySubscriptionRepository{
+		subscriptions: make(map[string]models.Subscription),
+		prices:        make(map[string][]priceChange),
+		deleted:       make(map[string]deletedSubscription),
+		idempotency:   make(map[idempotencyKey]models.IdempotencyRecord),
+	}
+}
+
+func (m *MemorySubscriptionRepository) Create(sub *models.Subscription, meta *models.AuditMeta) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.create(sub, meta)
+}
+
+// create mirrors Create, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) create(sub *models.Subscription, meta *models.AuditMeta) error {
+	if err := checkConstraints(sub); err != nil {
+		return fmt.Errorf("error creating subscription: %w", err)
+	}
+	after, err := snapshot(sub)
+	if err != nil {
+		return fmt.Errorf("error creating subscription: %w", err)
+	}
+	if _, ok := m.subscriptions[sub.Id]; ok || m.isDeleted(sub.Id) {
+		return fmt.Errorf("error creating subscription: %w",
+			&suberrors.ConstraintError{Constraint: constraintPrimaryKey, Err: suberrors.ErrSubscriptionConflict})
+	}
+	m.subscriptions[sub.Id] = *sub
+	m.order = append(m.order, sub.Id)
+	m.audit(sub.Id, models.AuditCreate, meta, nil, after)
+	return nil
+}
+
+func (m *MemorySubscriptionRepository) Read(id string) (*models.Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	sub = m.current(sub)
+	return &sub, nil
+}
+
+func (m *MemorySubscriptionRepository) Update(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.update(id, sub, match, meta)
+}
+
+// update mirrors Update, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) update(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error) {
+	if sub.StartDate != nil {
+		if _, err := timeparser.ParseMonthYear(*sub.StartDate); err != nil {
+			return nil, fmt.Errorf("error updating subscription: %w", err)
+		}
+	}
+	if sub.EndDate != nil {
+		if _, err := timeparser.ParseOptionalMonthYear(*sub.EndDate); err != nil {
+			return nil, fmt.Errorf("error updating subscription: %w", err)
+		}
+	}
+	updated, ok := m.subscriptions[id]
+	if !ok {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	if !match.Matches(updated.Version) {
+		return nil, versionConflictError(updated.Version)
+	}
+	updated.Version++
+	if sub.ServiceName != nil {
+		updated.ServiceName = *sub.ServiceName
+	}
+	if sub.BillingPeriod != nil {
+		updated.BillingPeriod = *sub.BillingPeriod
+	}
+	if sub.StartDate != nil {
+		updated.StartDate = *sub.StartDate
+	}
+	if sub.EndDate != nil {
+		updated.EndDate = *sub.EndDate
+	}
+	current := m.current(m.subscriptions[id])
+	// a price set once the subscription has been billed becomes a change
+	// effective from the current month, the months before keep their price
+	var change *money.Money
+	if sub.Price != nil {
+		if hasStarted(updated.StartDate) {
+			change = currentPriceChange(sub.Price, current.Price)
+		} else {
+			updated.Price.Amount = sub.Price.Amount
+			if sub.Price.Currency != "" {
+				updated.Price.Currency = sub.Price.Currency
+			}
+		}
+	}
+	if err := checkConstraints(&updated); err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if change != nil && change.Amount < 0 {
+		return nil, fmt.Errorf("failed to update subscription: %w",
+			&suberrors.ConstraintError{Constraint: constraintPriceChange, Err: suberrors.ErrConstraintViolation})
+	}
+	m.subscriptions[id] = updated
+	if change != nil {
+		m.setPrice(id, priceChange{effectiveFrom: currentMonth(), price: *change})
+	}
+	updated = m.current(updated)
+	before, err := snapshot(&current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	after, err := snapshot(&updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	m.audit(id, models.AuditUpdate, meta, before, after)
+	return &updated, nil
+}
+
+func (m *MemorySubscriptionRepository) Delete(id string, match *models.VersionMatch, meta *models.AuditMeta) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.delete(id, match, meta)
+}
+
+// delete mirrors Delete, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) delete(id string, match *models.VersionMatch, meta *models.AuditMeta) error {
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return suberrors.ErrIdSubscriptionNotFound
+	}
+	if !match.Matches(sub.Version) {
+		return versionConflictError(sub.Version)
+	}
+	current := m.current(sub)
+	before, err := snapshot(&current)
+	if err != nil {
+		return fmt.Errorf("error deleting subscription: %w", err)
+	}
+	sub.Version++
+	delete(m.subscriptions, id)
+	m.deleted[id] = deletedSubscription{sub: sub, deletedAt: time.Now()}
+	m.order = slices.DeleteFunc(m.order, func(orderId string) bool {
+		return orderId == id
+	})
+	m.audit(id, models.AuditDelete, meta, before, nil)
+	return nil
+}
+
+func (m *MemorySubscriptionRepository) ListSubscriptions(userId string) ([]*models.Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var subscriptions []*models.Subscription
+	for _, id := range m.order {
+		sub := m.current(m.subscriptions[id])
+		if sub.UserId == userId {
+			subscriptions = append(subscriptions, &sub)
+		}
+	}
+	if len(subscriptions) == 0 {
+		return nil, suberrors.ErrUserIdNotFound
+	}
+	slices.SortFunc(subscriptions, func(a, b *models.Subscription) int {
+		if result := compareSortValues("start_date", a.StartDate, b.StartDate); result != 0 {
+			return result
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return subscriptions, nil
+}
+
+func (m *MemorySubscriptionRepository) SearchSubscriptions(filter *models.SubscriptionFilter) (*models.ListSubscriptionsResponse, error) {
+	field, desc, _ := ParseSort(filter.Sort)
+	compare := func(aValue string, aId string, bValue string, bId string) int {
+		result := compareSortValues(field, aValue, bValue)
+		if result == 0 {
+			result = strings.Compare(aId, bId)
+		}
+		if desc {
+			return -result
+		}
+		return result
+	}
+	var after *cursor.Cursor
+	if filter.Cursor != "" {
+		c, err := cursor.Decode(filter.Cursor)
+		if err != nil {
+			return nil, searchError(err)
+		}
+		after = &c
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var subscriptions []*models.Subscription
+	for _, id := range m.order {
+		sub := m.current(m.subscriptions[id])
+		ok, err := matchesFilter(&sub, filter)
+		if err != nil {
+			return nil, searchError(err)
+		}
+		if !ok {
+			continue
+		}
+		if after != nil && compare(cursorValue(&sub, field), sub.Id, after.Value, after.Id) <= 0 {
+			continue
+		}
+		subscriptions = append(subscriptions, &sub)
+	}
+	slices.SortFunc(subscriptions, func(a, b *models.Subscription) int {
+		return compare(cursorValue(a, field), a.Id, cursorValue(b, field), b.Id)
+	})
+	if len(subscriptions) > filter.Limit+1 {
+		subscriptions = subscriptions[:filter.Limit+1]
+	}
+	return paginate(subscriptions, filter, field), nil
+}
+
+func (m *MemorySubscriptionRepository) CalculateSumSubscriptions(filter *models.ReportFilter) (*models.SumSubscriptionsResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	charges, err := m.charges(filter)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating sum subscriptions: %w", err)
+	}
+	if err := m.checkUserExists(filter.UserId); err != nil {
+		return nil, err
+	}
+	var sum models.SumSubscriptionsResponse
+	sum.Currency, err = chargesCurrency(filter, currencies(charges))
+	if err != nil {
+		return nil, err
+	}
+	for _, charge := range charges {
+		sum.Sum += charge.amount
+		sum.Months++
+	}
+	return &sum, nil
+}
+
+func (m *MemorySubscriptionRepository) MonthlyReport(filter *models.ReportFilter) (*models.MonthlyReport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	charges, err := m.charges(filter)
+	if err != nil {
+		return nil, fmt.Errorf("error building monthly report: %w", err)
+	}
+	if err := m.checkUserExists(filter.UserId); err != nil {
+		return nil, err
+	}
+	currency, err := chargesCurrency(filter, currencies(charges))
+	if err != nil {
+		return nil, err
+	}
+	type key struct {
+		month       time.Time
+		serviceName string
+	}
+	byKey := make(map[key]*serviceMonthTotal)
+	var totals []*serviceMonthTotal
+	for _, charge := range charges {
+		k := key{month: charge.month, serviceName: charge.sub.ServiceName}
+		total, ok := byKey[k]
+		if !ok {
+			total = &serviceMonthTotal{month: charge.month, serviceName: charge.sub.ServiceName}
+			byKey[k] = total
+			totals = append(totals, total)
+		}
+		total.total += charge.amount
+	}
+	slices.SortFunc(totals, func(a, b *serviceMonthTotal) int {
+		if result := a.month.Compare(b.month); result != 0 {
+			return result
+		}
+		return strings.Compare(a.serviceName, b.serviceName)
+	})
+	ordered := make([]serviceMonthTotal, 0, len(totals))
+	for _, total := range totals {
+		ordered = append(ordered, *total)
+	}
+	return monthlyReport(filter, currency, ordered)
+}
+
+func (m *MemorySubscriptionRepository) AggregateSubscriptions(filter *models.AggregateFilter) (*models.AggregateReport, error) {
+	if !IsValidGroupBy(filter.GroupBy) {
+		return nil, fmt.Errorf("error aggregating subscriptions: unknown group_by %q", filter.GroupBy)
+	}
+	if _, _, ok := ParseAggregateOrder(filter.OrderBy); !ok {
+		return nil, fmt.Errorf("error aggregating subscriptions: unknown order_by %q", filter.OrderBy)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	charges, err := m.charges(&filter.ReportFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating subscriptions: %w", err)
+	}
+	if err := m.checkUserExists(filter.UserId); err != nil {
+		return nil, err
+	}
+	currency, err := chargesCurrency(&filter.ReportFilter, currencies(charges))
+	if err != nil {
+		return nil, err
+	}
+	return &models.AggregateReport{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Currency:  currency,
+		GroupBy:   filter.GroupBy,
+		Groups:    aggregateCharges(filter, charges),
+	}, nil
+}
+
+func (m *MemorySubscriptionRepository) SaveExchangeRates(rates []*models.ExchangeRate) error {
+	saved := make([]exchangeRate, 0, len(rates))
+	for _, rate := range rates {
+		effectiveFrom, err := timeparser.ParseMonthYear(rate.EffectiveFrom)
+		if err != nil {
+			return fmt.Errorf("error saving exchange rates: %w", err)
+		}
+		saved = append(saved, exchangeRate{
+			base:          rate.Base,
+			quote:         rate.Quote,
+			rate:          rate.Rate,
+			effectiveFrom: effectiveFrom,
+		})
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rate := range saved {
+		i := slices.IndexFunc(m.rates, func(existing exchangeRate) bool {
+			return existing.base == rate.base && existing.quote == rate.quote && existing.effectiveFrom.Equal(rate.effectiveFrom)
+		})
+		if i >= 0 {
+			m.rates[i] = rate
+			continue
+		}
+		m.rates = append(m.rates, rate)
+	}
+	return nil
+}
+
+func (m *MemorySubscriptionRepository) ListExchangeRates() ([]*models.ExchangeRate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sorted := slices.Clone(m.rates)
+	slices.SortFunc(sorted, func(a, b exchangeRate) int {
+		return cmp.Or(
+			strings.Compare(a.base, b.base),
+			strings.Compare(a.quote, b.quote),
+			a.effectiveFrom.Compare(b.effectiveFrom),
+		)
+	})
+	rates := make([]*models.ExchangeRate, 0, len(sorted))
+	for _, rate := range sorted {
+		rates = append(rates, &models.ExchangeRate{
+			Base:          rate.base,
+			Quote:         rate.quote,
+			Rate:          rate.rate,
+			EffectiveFrom: rate.effectiveFrom.Format("01-2006"),
+		})
+	}
+	return rates, nil
+}
+
+// exchangeRate mirrors the rate lookup of chargesQuery: the latest rate of
+// the pair effective in month, or the inverse of the opposite one. m.mu must
+// be held by the caller.
+func (m *MemorySubscriptionRepository) exchangeRate(from string, to string, month time.Time) (float64, bool) {
+	var found *exchangeRate
+	var rate float64
+	for i := range m.rates {
+		candidate := &m.rates[i]
+		if candidate.effectiveFrom.After(month) {
+			continue
+		}
+		direct := candidate.base == from && candidate.quote == to
+		inverse := candidate.base == to && candidate.quote == from
+		if !direct && !inverse {
+			continue
+		}
+		if found != nil && (candidate.effectiveFrom.Before(found.effectiveFrom) ||
+			(candidate.effectiveFrom.Equal(found.effectiveFrom) && !direct)) {
+			continue
+		}
+		found = candidate
+		rate = candidate.rate
+		if inverse {
+			rate = 1 / candidate.rate
+		}
+	}
+	return rate, found != nil
+}
+
+func (m *MemorySubscriptionRepository) isDeleted(id string) bool {
+	_, ok := m.deleted[id]
+	return ok
+}
+
+// checkUserExists mirrors SubscriptionRepository.checkUserExists, m.mu must
+// be held by the caller.
+func (m *MemorySubscriptionRepository) checkUserExists(userId string) error {
+	if userId == "" {
+		return nil
+	}
+	for _, sub := range m.subscriptions {
+		if sub.UserId == userId {
+			return nil
+		}
+	}
+	return suberrors.ErrUserIdNotFound
+}
+
+// matchesFilter mirrors the WHERE clause built by SubscriptionRepository.SearchSubscriptions.
+func matchesFilter(sub *models.Subscription, filter *models.SubscriptionFilter) (bool, error) {
+	if filter.UserId != "" && sub.UserId != filter.UserId {
+		return false, nil
+	}
+	if filter.ServiceName != "" && sub.ServiceName != filter.ServiceName {
+		return false, nil
+	}
+	if filter.MinPrice != nil && sub.Price.Amount < *filter.MinPrice {
+		return false, nil
+	}
+	if filter.MaxPrice != nil && sub.Price.Amount > *filter.MaxPrice {
+		return false, nil
+	}
+	if filter.Currency != "" && sub.Price.Currency != filter.Currency {
+		return false, nil
+	}
+	if filter.BillingPeriod != "" && sub.BillingPeriod != filter.BillingPeriod {
+		return false, nil
+	}
+	stD, err := timeparser.ParseMonthYear(sub.StartDate)
+	if err != nil {
+		return false, err
+	}
+	endD, err := timeparser.ParseOptionalMonthYear(sub.EndDate)
+	if err != nil {
+		return false, err
+	}
+	dateFilters := []struct {
+		value   string
+		matches func(date time.Time) bool
+	}{
+		{filter.ActiveAt, func(date time.Time) bool { return !stD.After(date) && (endD == nil || !endD.Before(date)) }},
+		{filter.StartFrom, func(date time.Time) bool { return !stD.Before(date) }},
+		{filter.StartTo, func(date time.Time) bool { return !stD.After(date) }},
+		{filter.EndFrom, func(date time.Time) bool { return endD == nil || !endD.Before(date) }},
+		{filter.EndTo, func(date time.Time) bool { return endD != nil && !endD.After(date) }},
+	}
+	for _, dateFilter := range dateFilters {
+		if dateFilter.value == "" {
+			continue
+		}
+		date, err := timeparser.ParseMonthYear(dateFilter.value)
+		if err != nil {
+			return false, err
+		}
+		if !dateFilter.matches(date) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compareSortValues compares two cursor values of the given sort field.
+func compareSortValues(field string, a string, b string) int {
+	switch field {
+	case "price":
+		aPrice, _ := strconv.ParseInt(a, 10, 64)
+		bPrice, _ := strconv.ParseInt(b, 10, 64)
+		return cmp.Compare(aPrice, bPrice)
+	case "service_name":
+		return strings.Compare(a, b)
+	default:
+		aDate, _ := timeparser.ParseMonthYear(a)
+		bDate, _ := timeparser.ParseMonthYear(b)
+		return aDate.Compare(bDate)
+	}
+}
+
+// checkConstraints mirrors the CHECK constraints of the subscriptions table.
+func checkConstraints(sub *models.Subscription) error {
+	if sub.Price.Amount < 0 {
+		return &suberrors.ConstraintError{Constraint: constraintPrice, Err: suberrors.ErrConstraintViolation}
+	}
+	if !money.IsValidCurrency(sub.Price.Currency) {
+		return &suberrors.ConstraintError{Constraint: constraintCurrency, Err: suberrors.ErrConstraintViolation}
+	}
+	if _, ok := billingMonths[sub.BillingPeriod]; !ok {
+		return &suberrors.ConstraintError{Constraint: constraintBilling, Err: suberrors.ErrConstraintViolation}
+	}
+	stD, err := timeparser.ParseMonthYear(sub.StartDate)
+	if err != nil {
+		return err
+	}
+	endD, err := timeparser.ParseOptionalMonthYear(sub.EndDate)
+	if err != nil {
+		return err
+	}
+	if endD != nil && endD.Before(stD) {
+		return &suberrors.ConstraintError{Constraint: constraintDates, Err: suberrors.ErrConstraintViolation}
+	}
+	return nil
+}
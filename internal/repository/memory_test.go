@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// testMeta is the audit metadata of the mutations made by tests.
+var testMeta = &models.AuditMeta{Actor: "test", RequestId: "test-request"}
+
+func newTestRepository(t *testing.T, subs ...models.Subscription) *MemorySubscriptionRepository {
+	t.Helper()
+	repo := NewMemorySubscriptionRepository()
+	for _, sub := range subs {
+		if sub.BillingPeriod == "" {
+			sub.BillingPeriod = models.BillingMonthly
+		}
+		if err := repo.Create(&sub, testMeta); err != nil {
+			t.Fatalf("Create(%+v): %v", sub, err)
+		}
+	}
+	return repo
+}
+
+func TestMemoryCRUD(t *testing.T) {
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025"})
+
+	sub, err := repo.Read("1")
+	if err != nil || sub.ServiceName != "Netflix" || sub.EndDate != "" {
+		t.Fatalf("Read = %+v, %v", sub, err)
+	}
+
+	price, endDate := money.Money{Amount: 500}, "12-2025"
+	updated, err := repo.Update("1", &models.UpdateSubscription{Price: &price, EndDate: &endDate}, nil, testMeta)
+	// a price without currency keeps the current one
+	if err != nil || updated.Price != (money.Money{Amount: 500, Currency: "RUB"}) || updated.EndDate != "12-2025" || updated.ServiceName != "Netflix" {
+		t.Fatalf("Update = %+v, %v", updated, err)
+	}
+
+	if err := repo.Delete("1", nil, testMeta); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.Read("1"); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("Read after Delete error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+	if _, err := repo.Update("1", &models.UpdateSubscription{Price: &price}, nil, testMeta); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("Update after Delete error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+	if err := repo.Delete("1", nil, testMeta); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("Delete after Delete error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+}
+
+func TestMemoryListSubscriptions(t *testing.T) {
+	repo := newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025"},
+		models.Subscription{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 200, Currency: "RUB"}, UserId: "user456", StartDate: "07-2025"},
+		models.Subscription{Id: "3", ServiceName: "Apple", Price: money.Money{Amount: 100, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025"},
+	)
+	subs, err := repo.ListSubscriptions("user123")
+	if err != nil || len(subs) != 2 || subs[0].Id != "1" || subs[1].Id != "3" {
+		t.Fatalf("ListSubscriptions = %v, %v", subs, err)
+	}
+	if _, err := repo.ListSubscriptions("nobody"); !errors.Is(err, suberrors.ErrUserIdNotFound) {
+		t.Errorf("ListSubscriptions(nobody) error = %v, want ErrUserIdNotFound", err)
+	}
+}
+
+func TestMemoryCalculateSumSubscriptions(t *testing.T) {
+	repo := newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025", EndDate: "09-2025"},
+		models.Subscription{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 200, Currency: "RUB"}, UserId: "user123", StartDate: "11-2025"},
+		models.Subscription{Id: "3", ServiceName: "Netflix", Price: money.Money{Amount: 500, Currency: "RUB"}, UserId: "user456", StartDate: "01-2024", EndDate: "12-2024"},
+	)
+	tests := []struct {
+		name        string
+		userId      string
+		serviceName string
+		startDate   string
+		endDate     string
+		sum         int64
+		months      int
+	}{
+		// 3 months of Netflix and 2 months of the open-ended Spotify
+		{name: "user", userId: "user123", startDate: "01-2025", endDate: "12-2025", sum: 3*400 + 2*200, months: 5},
+		{name: "clipped at both ends", userId: "user123", startDate: "08-2025", endDate: "11-2025", sum: 2*400 + 200, months: 3},
+		{name: "open-ended runs through the window", userId: "user123", serviceName: "Spotify", startDate: "01-2026", endDate: "06-2026", sum: 6 * 200, months: 6},
+		{name: "service across users", serviceName: "Netflix", startDate: "12-2024", endDate: "07-2025", sum: 500 + 400, months: 2},
+		{name: "nothing overlaps", userId: "user456", startDate: "01-2025", endDate: "12-2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.CalculateSumSubscriptions(&models.ReportFilter{UserId: tt.userId, ServiceName: tt.serviceName, StartDate: tt.startDate, EndDate: tt.endDate})
+			if err != nil {
+				t.Fatalf("CalculateSumSubscriptions: %v", err)
+			}
+			if got.Sum != tt.sum || got.Months != tt.months {
+				t.Errorf("CalculateSumSubscriptions = %+v, want sum %d over %d months", got, tt.sum, tt.months)
+			}
+		})
+	}
+	if _, err := repo.CalculateSumSubscriptions(&models.ReportFilter{UserId: "nobody", StartDate: "01-2025", EndDate: "12-2025"}); !errors.Is(err, suberrors.ErrUserIdNotFound) {
+		t.Errorf("CalculateSumSubscriptions(nobody) error = %v, want ErrUserIdNotFound", err)
+	}
+}
+
+func TestMemoryCalculateSumCurrencies(t *testing.T) {
+	repo := newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 99900, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"},
+		models.Subscription{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 999, Currency: "USD"}, UserId: "user123", StartDate: "01-2025"},
+	)
+	_, err := repo.CalculateSumSubscriptions(&models.ReportFilter{UserId: "user123", StartDate: "01-2025", EndDate: "03-2025"})
+	if !errors.Is(err, suberrors.ErrMixedCurrencies) {
+		t.Fatalf("CalculateSumSubscriptions error = %v, want ErrMixedCurrencies", err)
+	}
+	_, err = repo.CalculateSumSubscriptions(&models.ReportFilter{UserId: "user123", StartDate: "01-2025", EndDate: "03-2025", Currency: "RUB"})
+	if !errors.Is(err, suberrors.ErrExchangeRateNotFound) {
+		t.Fatalf("CalculateSumSubscriptions(RUB) without rates error = %v, want ErrExchangeRateNotFound", err)
+	}
+	got, err := repo.CalculateSumSubscriptions(&models.ReportFilter{UserId: "user123", ServiceName: "Netflix", StartDate: "01-2025", EndDate: "03-2025"})
+	if err != nil || got.Sum != 3*99900 || got.Currency != "RUB" {
+		t.Errorf("CalculateSumSubscriptions(Netflix) = %+v, %v", got, err)
+	}
+
+	err = repo.SaveExchangeRates([]*models.ExchangeRate{
+		{Base: "USD", Quote: "RUB", Rate: 90, EffectiveFrom: "01-2025"},
+		{Base: "USD", Quote: "RUB", Rate: 100, EffectiveFrom: "03-2025"},
+	})
+	if err != nil {
+		t.Fatalf("SaveExchangeRates: %v", err)
+	}
+	tests := []struct {
+		currency string
+		sum      int64
+	}{
+		// each month is converted by the rate effective in it
+		{currency: "RUB", sum: 3*99900 + 2*999*90 + 999*100},
+		// the inverse of a rate converts the opposite way
+		{currency: "USD", sum: 2*99900/90 + 99900/100 + 3*999},
+	}
+	for _, tt := range tests {
+		got, err := repo.CalculateSumSubscriptions(&models.ReportFilter{UserId: "user123", StartDate: "01-2025", EndDate: "03-2025", Currency: tt.currency})
+		if err != nil || got.Sum != tt.sum || got.Currency != tt.currency || got.Months != 6 {
+			t.Errorf("CalculateSumSubscriptions(%s) = %+v, %v, want sum %d", tt.currency, got, err, tt.sum)
+		}
+	}
+	_, err = repo.CalculateSumSubscriptions(&models.ReportFilter{UserId: "user123", StartDate: "01-2025", EndDate: "03-2025", Currency: "EUR"})
+	if !errors.Is(err, suberrors.ErrExchangeRateNotFound) {
+		t.Errorf("CalculateSumSubscriptions(EUR) error = %v, want ErrExchangeRateNotFound", err)
+	}
+}
+
+func TestMemoryExchangeRates(t *testing.T) {
+	repo := NewMemorySubscriptionRepository()
+	rates := []*models.ExchangeRate{
+		{Base: "USD", Quote: "RUB", Rate: 100, EffectiveFrom: "03-2025"},
+		{Base: "EUR", Quote: "RUB", Rate: 98, EffectiveFrom: "01-2025"},
+		{Base: "USD", Quote: "RUB", Rate: 90, EffectiveFrom: "01-2025"},
+	}
+	if err := repo.SaveExchangeRates(rates); err != nil {
+		t.Fatalf("SaveExchangeRates: %v", err)
+	}
+	// a rate of the same pair and month replaces the saved one
+	if err := repo.SaveExchangeRates([]*models.ExchangeRate{{Base: "USD", Quote: "RUB", Rate: 91, EffectiveFrom: "01-2025"}}); err != nil {
+		t.Fatalf("SaveExchangeRates: %v", err)
+	}
+	got, err := repo.ListExchangeRates()
+	if err != nil {
+		t.Fatalf("ListExchangeRates: %v", err)
+	}
+	var lines []string
+	for _, rate := range got {
+		lines = append(lines, fmt.Sprintf("%s/%s %g %s", rate.Base, rate.Quote, rate.Rate, rate.EffectiveFrom))
+	}
+	want := []string{"EUR/RUB 98 01-2025", "USD/RUB 91 01-2025", "USD/RUB 100 03-2025"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("ListExchangeRates = %q, want %q", lines, want)
+	}
+}
+
+func TestMemoryVersions(t *testing.T) {
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025", Version: 1})
+	name := "Netflix Premium"
+	updated, err := repo.Update("1", &models.UpdateSubscription{ServiceName: &name}, &models.VersionMatch{Versions: []int64{1}}, testMeta)
+	if err != nil || updated.Version != 2 {
+		t.Fatalf("Update = %+v, %v, want version 2", updated, err)
+	}
+	if _, err := repo.Update("1", &models.UpdateSubscription{ServiceName: &name}, &models.VersionMatch{Versions: []int64{1}}, testMeta); !errors.Is(err, suberrors.ErrVersionConflict) {
+		t.Errorf("Update of a stale version error = %v, want ErrVersionConflict", err)
+	}
+	if err := repo.Delete("1", &models.VersionMatch{Versions: []int64{1}}, testMeta); !errors.Is(err, suberrors.ErrVersionConflict) {
+		t.Errorf("Delete of a stale version error = %v, want ErrVersionConflict", err)
+	}
+	if sub, err := repo.Read("1"); err != nil || sub.Version != 2 {
+		t.Errorf("Read after conflicts = %+v, %v, want version 2", sub, err)
+	}
+	if err := repo.Delete("1", &models.VersionMatch{Any: true}, testMeta); err != nil {
+		t.Errorf("Delete with If-Match * error = %v", err)
+	}
+}
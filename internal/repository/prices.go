@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"TestEffectiveMobile/pkg/timeparser"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"slices"
+	"time"
+)
+
+// The price a subscription is created with is kept in the subscriptions
+// table and applies from its start month, every later price lives in
+// subscription_prices and applies from its effective_from month until the
+// next one. Changes effective in or before the start month are ignored.
+
+// currentSubscriptions replaces the subscriptions table in reads: price and
+// currency are the ones in effect in the current month, so a scheduled change
+// shows up once its month comes without any write, and deleted subscriptions
+// are left out.
+const currentSubscriptions = `(
+            SELECT s.id, s.service_name, COALESCE(cp.price, s.price) AS price, COALESCE(cp.currency, s.currency) AS currency,
+                s.billing_period, s.user_id, s.start_date, s.end_date, s.version
+            FROM subscriptions s
+            LEFT JOIN LATERAL (
+                SELECT price, currency FROM subscription_prices
+                WHERE subscription_id = s.id AND effective_from > s.start_date
+                    AND effective_from <= date_trunc('month', CURRENT_DATE)::date
+                ORDER BY effective_from DESC
+                LIMIT 1
+            ) cp ON true
+            WHERE s.deleted_at IS NULL
+        ) subscriptions`
+
+// pricesInEffect joins every month m of chargesQuery with "pr", the price and
+// currency of subscription s in effect in that month.
+const pricesInEffect = `
+            LEFT JOIN LATERAL (
+                SELECT price, currency FROM subscription_prices
+                WHERE subscription_id = s.id AND effective_from > s.start_date AND effective_from <= m.month
+                ORDER BY effective_from DESC
+                LIMIT 1
+            ) cp ON true
+            CROSS JOIN LATERAL (
+                SELECT COALESCE(cp.price, s.price) AS price, COALESCE(cp.currency, s.currency) AS currency
+            ) pr`
+
+const upsertPriceChange = `
+        INSERT INTO subscription_prices (subscription_id, effective_from, price, currency)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price, currency = EXCLUDED.currency
+    `
+
+func (s *SubscriptionRepository) SchedulePriceChange(id string, change *models.PriceChange, meta *models.AuditMeta) error {
+	if !isValidId(id) {
+		return suberrors.ErrIdSubscriptionNotFound
+	}
+	effectiveFrom, err := timeparser.ParseMonthYear(change.EffectiveFrom)
+	if err != nil {
+		return fmt.Errorf("error scheduling price change: %w", err)
+	}
+	after, err := snapshot(change)
+	if err != nil {
+		return fmt.Errorf("error scheduling price change: %w", err)
+	}
+	err = pgx.BeginFunc(s.ctx, s.db, func(tx pgx.Tx) error {
+		// the price shown by reads may change, so the version does too
+		res, err := tx.Exec(s.ctx,
+			"UPDATE subscriptions SET version = version + 1 WHERE id = $1 AND deleted_at IS NULL",
+			id)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return suberrors.ErrIdSubscriptionNotFound
+		}
+		_, err = tx.Exec(s.ctx, upsertPriceChange, id, effectiveFrom, change.Price.Amount, change.Price.Currency)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, id, models.AuditPriceChange, meta, nil, after)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, suberrors.ErrIdSubscriptionNotFound) || (errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation) {
+			return suberrors.ErrIdSubscriptionNotFound
+		}
+		return fmt.Errorf("error scheduling price change: %w", mapConstraintError(err))
+	}
+	return nil
+}
+
+func (s *SubscriptionRepository) ListPrices(id string) ([]*models.PriceChange, error) {
+	if !isValidId(id) {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	rows, err := s.db.Query(s.ctx, `
+        SELECT price, currency, start_date FROM subscriptions WHERE id = $1 AND deleted_at IS NULL
+        UNION ALL
+        SELECT p.price, p.currency, p.effective_from
+        FROM subscription_prices p
+        JOIN subscriptions s ON s.id = p.subscription_id
+        WHERE p.subscription_id = $1 AND p.effective_from > s.start_date AND s.deleted_at IS NULL
+        ORDER BY 3`,
+		id)
+	if err != nil {
+		return nil, fmt.Errorf("error listing prices: %w", err)
+	}
+	defer rows.Close()
+	var prices []*models.PriceChange
+	for rows.Next() {
+		var price models.PriceChange
+		var effectiveFrom time.Time
+		if err := rows.Scan(&price.Price.Amount, &price.Price.Currency, &effectiveFrom); err != nil {
+			return nil, fmt.Errorf("error scanning price: %w", err)
+		}
+		price.EffectiveFrom = effectiveFrom.Format("01-2006")
+		prices = append(prices, &price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if len(prices) == 0 {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	return prices, nil
+}
+
+// priceChange is a row of the subscription_prices table.
+type priceChange struct {
+	effectiveFrom time.Time
+	price         money.Money
+}
+
+func (m *MemorySubscriptionRepository) SchedulePriceChange(id string, change *models.PriceChange, meta *models.AuditMeta) error {
+	effectiveFrom, err := timeparser.ParseMonthYear(change.EffectiveFrom)
+	if err != nil {
+		return fmt.Errorf("error scheduling price change: %w", err)
+	}
+	if change.Price.Amount < 0 {
+		return fmt.Errorf("error scheduling price change: %w",
+			&suberrors.ConstraintError{Constraint: constraintPriceChange, Err: suberrors.ErrConstraintViolation})
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return suberrors.ErrIdSubscriptionNotFound
+	}
+	after, err := snapshot(change)
+	if err != nil {
+		return fmt.Errorf("error scheduling price change: %w", err)
+	}
+	m.setPrice(id, priceChange{effectiveFrom: effectiveFrom, price: change.Price})
+	sub.Version++
+	m.subscriptions[id] = sub
+	m.audit(id, models.AuditPriceChange, meta, nil, after)
+	return nil
+}
+
+func (m *MemorySubscriptionRepository) ListPrices(id string) ([]*models.PriceChange, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sub, ok := m.subscriptions[id]
+	if !ok {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	prices := []*models.PriceChange{{Price: sub.Price, EffectiveFrom: sub.StartDate}}
+	stD, err := timeparser.ParseMonthYear(sub.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("error listing prices: %w", err)
+	}
+	for _, change := range m.prices[id] {
+		if change.effectiveFrom.After(stD) {
+			prices = append(prices, &models.PriceChange{
+				Price:         change.price,
+				EffectiveFrom: change.effectiveFrom.Format("01-2006"),
+			})
+		}
+	}
+	return prices, nil
+}
+
+// setPrice mirrors upsertPriceChange, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) setPrice(id string, change priceChange) {
+	changes := m.prices[id]
+	i := slices.IndexFunc(changes, func(existing priceChange) bool {
+		return existing.effectiveFrom.Equal(change.effectiveFrom)
+	})
+	if i >= 0 {
+		changes[i] = change
+		return
+	}
+	changes = append(changes, change)
+	slices.SortFunc(changes, func(a, b priceChange) int {
+		return a.effectiveFrom.Compare(b.effectiveFrom)
+	})
+	m.prices[id] = changes
+}
+
+// priceAt mirrors pricesInEffect: the price of sub in effect in month, m.mu
+// must be held by the caller.
+func (m *MemorySubscriptionRepository) priceAt(sub *models.Subscription, month time.Time) money.Money {
+	price := sub.Price
+	stD, err := timeparser.ParseMonthYear(sub.StartDate)
+	if err != nil {
+		return price
+	}
+	for _, change := range m.prices[sub.Id] {
+		if change.effectiveFrom.After(stD) && !change.effectiveFrom.After(month) {
+			price = change.price
+		}
+	}
+	return price
+}
+
+// current mirrors currentSubscriptions, m.mu must be held by the caller.
+func (m *MemorySubscriptionRepository) current(sub models.Subscription) models.Subscription {
+	sub.Price = m.priceAt(&sub, currentMonth())
+	return sub
+}
+
+// currentMonth is the first day of the current month, the month whose price
+// reads return and a price changed by an update applies from.
+func currentMonth() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// hasStarted reports whether a subscription started in startDate is billed
+// before the current month, so that its price can only be changed from now
+// on instead of being replaced.
+func hasStarted(startDate string) bool {
+	stD, err := timeparser.ParseMonthYear(startDate)
+	if err != nil {
+		return false
+	}
+	return stD.Before(currentMonth())
+}
+
+// currentPriceChange returns the price an update sets from the current month
+// on, a price without currency keeps the currency in effect. It is nil when
+// the price is already in effect.
+func currentPriceChange(price *money.Money, inEffect money.Money) *money.Money {
+	change := *price
+	if change.Currency == "" {
+		change.Currency = inEffect.Currency
+	}
+	if change == inEffect {
+		return nil
+	}
+	return &change
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestMemoryPriceHistory(t *testing.T) {
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 1000, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"})
+	changes := []models.PriceChange{
+		{Price: money.Money{Amount: 3000, Currency: "RUB"}, EffectiveFrom: "06-2025"},
+		{Price: money.Money{Amount: 1500, Currency: "RUB"}, EffectiveFrom: "04-2025"},
+		// replaces the change scheduled for the same month
+		{Price: money.Money{Amount: 2000, Currency: "RUB"}, EffectiveFrom: "04-2025"},
+	}
+	for _, change := range changes {
+		if err := repo.SchedulePriceChange("1", &change, testMeta); err != nil {
+			t.Fatalf("SchedulePriceChange(%+v): %v", change, err)
+		}
+	}
+
+	prices, err := repo.ListPrices("1")
+	if err != nil {
+		t.Fatalf("ListPrices: %v", err)
+	}
+	var got []string
+	for _, price := range prices {
+		got = append(got, fmt.Sprintf("%s %d %s", price.EffectiveFrom, price.Price.Amount, price.Price.Currency))
+	}
+	want := []string{"01-2025 1000 RUB", "04-2025 2000 RUB", "06-2025 3000 RUB"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ListPrices = %q, want %q", got, want)
+	}
+
+	sum, err := repo.CalculateSumSubscriptions(&models.ReportFilter{StartDate: "01-2025", EndDate: "07-2025"})
+	if err != nil {
+		t.Fatalf("CalculateSumSubscriptions: %v", err)
+	}
+	// each month is billed by the price in effect in it
+	if want := int64(3*1000 + 2*2000 + 2*3000); sum.Sum != want {
+		t.Errorf("CalculateSumSubscriptions = %+v, want sum %d", sum, want)
+	}
+
+	report, err := repo.MonthlyReport(&models.ReportFilter{StartDate: "03-2025", EndDate: "06-2025"})
+	if err != nil {
+		t.Fatalf("MonthlyReport: %v", err)
+	}
+	wantLines := []string{"03-2025 1000 Netflix=1000", "04-2025 2000 Netflix=2000", "05-2025 2000 Netflix=2000", "06-2025 3000 Netflix=3000"}
+	if got := reportLines(report); !slices.Equal(got, wantLines) {
+		t.Errorf("MonthlyReport = %q, want %q", got, wantLines)
+	}
+
+	if err := repo.SchedulePriceChange("2", &changes[0], testMeta); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("SchedulePriceChange of an unknown subscription error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+}
+
+func TestMemoryUpdatePriceKeepsHistory(t *testing.T) {
+	now := currentMonth()
+	start := now.AddDate(-1, 0, 0)
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 1000, Currency: "USD"}, UserId: "user123", StartDate: start.Format("01-2006")})
+	past := &models.ReportFilter{StartDate: start.Format("01-2006"), EndDate: now.AddDate(0, -1, 0).Format("01-2006")}
+	before, err := repo.CalculateSumSubscriptions(past)
+	if err != nil {
+		t.Fatalf("CalculateSumSubscriptions: %v", err)
+	}
+
+	updated, err := repo.Update("1", &models.UpdateSubscription{Price: &money.Money{Amount: 2000}}, nil, testMeta)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	// a price without currency keeps the current one
+	if updated.Price != (money.Money{Amount: 2000, Currency: "USD"}) {
+		t.Errorf("Update price = %+v, want 2000 USD", updated.Price)
+	}
+	read, err := repo.Read("1")
+	if err != nil || read.Price != updated.Price {
+		t.Errorf("Read after Update = %+v, %v, want price %+v", read, err, updated.Price)
+	}
+	prices, err := repo.ListPrices("1")
+	if err != nil || len(prices) != 2 || prices[0].Price.Amount != 1000 || prices[1].EffectiveFrom != now.Format("01-2006") {
+		t.Errorf("ListPrices after Update = %+v, %v, want the base price and the current month", prices, err)
+	}
+
+	after, err := repo.CalculateSumSubscriptions(past)
+	if err != nil {
+		t.Fatalf("CalculateSumSubscriptions: %v", err)
+	}
+	if *after != *before || before.Sum != 12*1000 {
+		t.Errorf("past months sum changed after Update: %+v, was %+v", after, before)
+	}
+	current, err := repo.CalculateSumSubscriptions(&models.ReportFilter{StartDate: now.Format("01-2006"), EndDate: now.Format("01-2006")})
+	if err != nil || current.Sum != 2000 {
+		t.Errorf("current month sum after Update = %+v, %v, want 2000", current, err)
+	}
+
+	// the price in effect is not a change
+	if _, err := repo.Update("1", &models.UpdateSubscription{Price: &money.Money{Amount: 2000, Currency: "USD"}}, nil, testMeta); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if prices, err := repo.ListPrices("1"); err != nil || len(prices) != 2 {
+		t.Errorf("ListPrices after Update with the same price = %+v, %v, want 2 prices", prices, err)
+	}
+}
+
+func TestMemoryUpdatePriceBeforeStart(t *testing.T) {
+	start := currentMonth().AddDate(0, 1, 0).Format("01-2006")
+	repo := newTestRepository(t, models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 1000, Currency: "USD"}, UserId: "user123", StartDate: start})
+
+	// nothing has been billed yet, so the price the subscription starts with is replaced
+	updated, err := repo.Update("1", &models.UpdateSubscription{Price: &money.Money{Amount: 2000}}, nil, testMeta)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Price != (money.Money{Amount: 2000, Currency: "USD"}) {
+		t.Errorf("Update price = %+v, want 2000 USD", updated.Price)
+	}
+	prices, err := repo.ListPrices("1")
+	if err != nil || len(prices) != 1 || prices[0].Price != updated.Price || prices[0].EffectiveFrom != start {
+		t.Errorf("ListPrices after Update = %+v, %v, want only the corrected base price", prices, err)
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// reportLines renders every month of a report as "MM-YYYY total service=total ...".
+func reportLines(report *models.MonthlyReport) []string {
+	var lines []string
+	for _, month := range report.Months {
+		line := fmt.Sprintf("%s %d", month.Month, month.Total)
+		for _, service := range month.Services {
+			line += fmt.Sprintf(" %s=%d", service.ServiceName, service.Total)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestMemoryMonthlyReport(t *testing.T) {
+	repo := newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "alice", StartDate: "01-2025", EndDate: "02-2025"},
+		models.Subscription{Id: "2", ServiceName: "Apple", Price: money.Money{Amount: 100, Currency: "RUB"}, UserId: "alice", StartDate: "02-2025"},
+		models.Subscription{Id: "3", ServiceName: "Netflix", Price: money.Money{Amount: 500, Currency: "RUB"}, UserId: "bob", StartDate: "02-2025"},
+	)
+	tests := []struct {
+		name   string
+		filter models.ReportFilter
+		total  int
+		want   []string
+	}{
+		{
+			name:   "all users",
+			filter: models.ReportFilter{StartDate: "01-2025", EndDate: "04-2025"},
+			total:  400 + (400 + 100 + 500) + 2*(100+500),
+			// months without charges are reported with an empty breakdown
+			want: []string{"01-2025 400 Netflix=400", "02-2025 1000 Apple=100 Netflix=900", "03-2025 600 Apple=100 Netflix=500", "04-2025 600 Apple=100 Netflix=500"},
+		},
+		{
+			name:   "user and service",
+			filter: models.ReportFilter{UserId: "alice", ServiceName: "Netflix", StartDate: "12-2024", EndDate: "03-2025"},
+			total:  800,
+			want:   []string{"12-2024 0", "01-2025 400 Netflix=400", "02-2025 400 Netflix=400", "03-2025 0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := repo.MonthlyReport(&tt.filter)
+			if err != nil {
+				t.Fatalf("MonthlyReport: %v", err)
+			}
+			if got := reportLines(report); !slices.Equal(got, tt.want) || int(report.Total) != tt.total {
+				t.Errorf("MonthlyReport total %d, months %q, want %d, %q", report.Total, got, tt.total, tt.want)
+			}
+		})
+	}
+	_, err := repo.MonthlyReport(&models.ReportFilter{UserId: "nobody", StartDate: "01-2025", EndDate: "04-2025"})
+	if !errors.Is(err, suberrors.ErrUserIdNotFound) {
+		t.Errorf("MonthlyReport(nobody) error = %v, want ErrUserIdNotFound", err)
+	}
+}
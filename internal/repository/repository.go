@@ -2,158 +2,318 @@ package repository
 
 import (
 	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/cursor"
+	"TestEffectiveMobile/pkg/money"
 	"TestEffectiveMobile/pkg/suberrors"
 	"TestEffectiveMobile/pkg/timeparser"
 	"context"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strings"
 	"time"
 )
 
 type SubscriptionRepositoryInterface interface {
-	Create(sub *models.Subscription) error
+	Create(sub *models.Subscription, meta *models.AuditMeta) error
 	Read(id string) (*models.Subscription, error)
-	Update(id string, sub *models.UpdateSubscription) error
-	Delete(id string) error
+	Update(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error)
+	Delete(id string, match *models.VersionMatch, meta *models.AuditMeta) error
 	ListSubscriptions(userId string) ([]*models.Subscription, error)
-	CalculateSumSubscriptions(userId string, startDate string, endDate string, serviceName string) (int, error)
+	SearchSubscriptions(filter *models.SubscriptionFilter) (*models.ListSubscriptionsResponse, error)
+	CalculateSumSubscriptions(filter *models.ReportFilter) (*models.SumSubscriptionsResponse, error)
+	MonthlyReport(filter *models.ReportFilter) (*models.MonthlyReport, error)
+	AggregateSubscriptions(filter *models.AggregateFilter) (*models.AggregateReport, error)
+	SaveExchangeRates(rates []*models.ExchangeRate) error
+	ListExchangeRates() ([]*models.ExchangeRate, error)
+	SchedulePriceChange(id string, change *models.PriceChange, meta *models.AuditMeta) error
+	ListPrices(id string) ([]*models.PriceChange, error)
+	History(id string) ([]*models.AuditEntry, error)
+	Restore(id string, meta *models.AuditMeta) (*models.Subscription, error)
+	Purge(deletedBefore time.Time, meta *models.AuditMeta) (int64, error)
+	BulkCreate(subs []*models.Subscription, atomic bool, meta *models.AuditMeta) ([]error, error)
+	BulkUpdate(items []*models.BulkUpdateItem, atomic bool, meta *models.AuditMeta) ([]*models.Subscription, []error, error)
+	BulkDelete(items []*models.BulkDeleteItem, atomic bool, meta *models.AuditMeta) ([]error, error)
+	ExportSubscriptions(filter *models.SubscriptionFilter, visit func(sub *models.Subscription) error) error
+	ReserveIdempotencyKey(record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	SaveIdempotentResponse(record *models.IdempotencyRecord) error
+	ReleaseIdempotencyKey(scope string, key string) error
+	PurgeIdempotencyKeys() (int64, error)
 }
 
+// selectSubscription reads a subscription by id as it is returned to clients.
+const selectSubscription = "SELECT id, service_name, price, currency, billing_period, user_id, start_date, end_date, version FROM " +
+	currentSubscriptions + " WHERE id = $1"
+
+// Names of the subscriptions and subscription_prices tables constraints, see migrations.
+const (
+	constraintPrimaryKey  = "subscriptions_pkey"
+	constraintPrice       = "subscriptions_price_check"
+	constraintDates       = "subscriptions_dates_check"
+	constraintCurrency    = "subscriptions_currency_check"
+	constraintBilling     = "subscriptions_billing_period_check"
+	constraintPriceChange = "subscription_prices_price_check"
+)
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation           = "23505"
+	pgForeignKeyViolation       = "23503"
+	pgCheckViolation            = "23514"
+	pgInvalidTextRepresentation = "22P02"
+)
+
 type SubscriptionRepository struct {
-	db  *pgx.Conn
+	db  *pgxpool.Pool
 	ctx context.Context
 }
 
-func NewSubscriptionRepository(db *pgx.Conn, ctx context.Context) *SubscriptionRepository {
+func NewSubscriptionRepository(db *pgxpool.Pool, ctx context.Context) *SubscriptionRepository {
 	return &SubscriptionRepository{
 		db:  db,
 		ctx: ctx,
 	}
 }
 
-func (s *SubscriptionRepository) Create(sub *models.Subscription) error {
-	stD, err := timeparser.ParseMonthYear(sub.StartDate)
+const insertSubscription = "INSERT INTO subscriptions (id,service_name, price, currency, billing_period, user_id, start_date, end_date) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+
+func (s *SubscriptionRepository) Create(sub *models.Subscription, meta *models.AuditMeta) error {
+	args, after, err := insertArgs(sub)
 	if err != nil {
 		return fmt.Errorf("error creating subscription: %w", err)
 	}
-	endD, err := timeparser.ParseMonthYear(sub.EndDate)
+	err = pgx.BeginFunc(s.ctx, s.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(s.ctx, insertSubscription, args...); err != nil {
+			return err
+		}
+		return s.audit(tx, sub.Id, models.AuditCreate, meta, nil, after)
+	})
 	if err != nil {
-		return fmt.Errorf("error creating subscription: %w", err)
+		return fmt.Errorf("error creating subscription: %w", mapConstraintError(err))
 	}
-	_, err = s.db.Exec(s.ctx,
-		"INSERT INTO subscriptions (id,service_name, price, user_id, start_date, end_date) VALUES ($1, $2, $3, $4, $5, $6)",
+	return nil
+}
+
+// insertArgs returns the arguments of insertSubscription for sub and its
+// snapshot for the audit entry.
+func insertArgs(sub *models.Subscription) ([]any, []byte, error) {
+	stD, err := timeparser.ParseMonthYear(sub.StartDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	endD, err := timeparser.ParseOptionalMonthYear(sub.EndDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := snapshot(sub)
+	if err != nil {
+		return nil, nil, err
+	}
+	args := []any{
 		sub.Id,
 		sub.ServiceName,
-		sub.Price,
+		sub.Price.Amount,
+		sub.Price.Currency,
+		sub.BillingPeriod,
 		sub.UserId,
 		stD,
-		endD)
-	if err != nil {
-		return fmt.Errorf("error creating subscription: %w", err)
+		endD,
 	}
-	return err
+	return args, after, nil
 }
 
 func (s *SubscriptionRepository) Read(id string) (*models.Subscription, error) {
-	var sub models.Subscription
-	var startDate, endDate time.Time
-	err := s.db.QueryRow(s.ctx,
-		"SELECT id, service_name, price, user_id, start_date, end_date FROM subscriptions WHERE id = $1",
-		id).Scan(
-		&sub.Id,
-		&sub.ServiceName,
-		&sub.Price,
-		&sub.UserId,
-		&startDate,
-		&endDate)
+	if !isValidId(id) {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	sub, err := scanSubscription(s.db.QueryRow(s.ctx, selectSubscription, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, suberrors.ErrIdSubscriptionNotFound
 		}
 		return nil, fmt.Errorf("error reading subscription: %w", err)
 	}
-	sub.StartDate = startDate.Format("01-2006")
-	sub.EndDate = endDate.Format("01-2006")
-	sub.Id = id
-	return &sub, nil
+	return sub, nil
 }
 
-func (s *SubscriptionRepository) Update(id string, sub *models.UpdateSubscription) error {
+func (s *SubscriptionRepository) Update(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error) {
+	if !isValidId(id) {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	var updated *models.Subscription
+	err := pgx.BeginFunc(s.ctx, s.db, func(tx pgx.Tx) error {
+		var err error
+		updated, err = s.update(tx, id, sub, match, meta)
+		return err
+	})
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return updated, nil
+}
+
+// update applies sub to the subscription id within tx.
+func (s *SubscriptionRepository) update(tx pgx.Tx, id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error) {
+	// a price set once the subscription has been billed becomes a change
+	// effective from the current month, the months before keep their price
 	const query = `
         UPDATE subscriptions 
         SET 
             service_name = COALESCE($1, service_name),
             price = COALESCE($2, price),
-            start_date = COALESCE($3, start_date),
-            end_date = COALESCE($4, end_date)
-        WHERE id = $5
-        RETURNING id
+            currency = COALESCE($3, currency),
+            billing_period = COALESCE($4, billing_period),
+            start_date = COALESCE($5, start_date),
+            end_date = CASE WHEN $6::boolean THEN $7::date ELSE end_date END,
+            version = version + 1
+        WHERE id = $8
     `
-	stD, err := timeparser.ParseMonthYear(sub.StartDate)
-	if err != nil {
-		return fmt.Errorf("error updating subscription: %w", err)
+	var stD, endD *time.Time
+	if sub.StartDate != nil {
+		parsed, err := timeparser.ParseMonthYear(*sub.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		stD = &parsed
 	}
-	endD, err := timeparser.ParseMonthYear(sub.EndDate)
-	if err != nil {
-		return fmt.Errorf("error updating subscription: %w", err)
+	if sub.EndDate != nil {
+		parsed, err := timeparser.ParseOptionalMonthYear(*sub.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		endD = parsed
 	}
 
-	var updatedID string
-	err = s.db.QueryRow(s.ctx, query,
+	current, err := s.lockSubscription(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !match.Matches(current.Version) {
+		return nil, versionConflictError(current.Version)
+	}
+	var amount *int64
+	var currency *string
+	var change *money.Money
+	if sub.Price != nil {
+		startDate := current.StartDate
+		if sub.StartDate != nil {
+			startDate = *sub.StartDate
+		}
+		if hasStarted(startDate) {
+			change = currentPriceChange(sub.Price, current.Price)
+		} else {
+			amount = &sub.Price.Amount
+			if sub.Price.Currency != "" {
+				currency = &sub.Price.Currency
+			}
+		}
+	}
+	_, err = tx.Exec(s.ctx, query,
 		sub.ServiceName,
-		sub.Price,
+		amount,
+		currency,
+		sub.BillingPeriod,
 		stD,
+		sub.EndDate != nil,
 		endD,
 		id,
-	).Scan(&updatedID)
+	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return suberrors.ErrIdSubscriptionNotFound
+		return nil, err
+	}
+	if change != nil {
+		if _, err := tx.Exec(s.ctx, upsertPriceChange, id, currentMonth(), change.Amount, change.Currency); err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	updated, err := scanSubscription(tx.QueryRow(s.ctx, selectSubscription, id))
+	if err != nil {
+		return nil, err
+	}
+	before, err := snapshot(current)
+	if err != nil {
+		return nil, err
+	}
+	after, err := snapshot(updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, s.audit(tx, id, models.AuditUpdate, meta, before, after)
+}
+
+// updateError maps an error of update to the error returned to the service.
+func updateError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return suberrors.ErrIdSubscriptionNotFound
+	}
+	if errors.Is(err, suberrors.ErrVersionConflict) {
+		return err
+	}
+	return fmt.Errorf("failed to update subscription: %w", mapConstraintError(err))
+}
+
+func (s *SubscriptionRepository) Delete(id string, match *models.VersionMatch, meta *models.AuditMeta) error {
+	if !isValidId(id) {
+		return suberrors.ErrIdSubscriptionNotFound
+	}
+	err := pgx.BeginFunc(s.ctx, s.db, func(tx pgx.Tx) error {
+		return s.delete(tx, id, match, meta)
+	})
+	if err != nil {
+		return deleteError(err)
 	}
 	return nil
 }
 
-func (s *SubscriptionRepository) Delete(id string) error {
-	res, err := s.db.Exec(s.ctx,
-		"DELETE FROM subscriptions WHERE id = $1",
-		id)
+// delete soft-deletes the subscription id within tx.
+func (s *SubscriptionRepository) delete(tx pgx.Tx, id string, match *models.VersionMatch, meta *models.AuditMeta) error {
+	current, err := s.lockSubscription(tx, id)
 	if err != nil {
-		return fmt.Errorf("error deleting subscription: %w", err)
+		return err
 	}
-	if res.RowsAffected() == 0 {
+	if !match.Matches(current.Version) {
+		return versionConflictError(current.Version)
+	}
+	if _, err := tx.Exec(s.ctx, "UPDATE subscriptions SET deleted_at = now(), version = version + 1 WHERE id = $1", id); err != nil {
+		return err
+	}
+	before, err := snapshot(current)
+	if err != nil {
+		return err
+	}
+	return s.audit(tx, id, models.AuditDelete, meta, before, nil)
+}
+
+// deleteError maps an error of delete to the error returned to the service.
+func deleteError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return suberrors.ErrIdSubscriptionNotFound
 	}
-	return nil
+	if errors.Is(err, suberrors.ErrVersionConflict) {
+		return err
+	}
+	return fmt.Errorf("error deleting subscription: %w", err)
 }
 
 func (s *SubscriptionRepository) ListSubscriptions(userId string) ([]*models.Subscription, error) {
 	var subscriptions []*models.Subscription
 
 	rows, err := s.db.Query(s.ctx,
-		"SELECT id, service_name, price, user_id, start_date, end_date FROM subscriptions WHERE user_id = $1",
+		"SELECT id, service_name, price, currency, billing_period, user_id, start_date, end_date, version FROM "+currentSubscriptions+" WHERE user_id = $1 ORDER BY start_date, id",
 		userId)
 	if err != nil {
 		return nil, fmt.Errorf("error listing subscriptions: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var startDate, endDate time.Time
-		var sub models.Subscription
-		err := rows.Scan(&sub.Id,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserId,
-			&startDate,
-			&endDate)
+		sub, err := scanSubscription(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning subscription: %w", err)
 		}
-		sub.StartDate = startDate.Format("01-2006")
-		sub.EndDate = endDate.Format("01-2006")
-		subscriptions = append(subscriptions, &sub)
+		subscriptions = append(subscriptions, sub)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -165,48 +325,333 @@ func (s *SubscriptionRepository) ListSubscriptions(userId string) ([]*models.Sub
 	return subscriptions, nil
 }
 
-func (s *SubscriptionRepository) CalculateSumSubscriptions(userId string, startDate string, endDate string, serviceName string) (int, error) {
-	var sum int
-	stD, err := timeparser.ParseMonthYear(startDate)
+func (s *SubscriptionRepository) SearchSubscriptions(filter *models.SubscriptionFilter) (*models.ListSubscriptionsResponse, error) {
+	field, desc, _ := ParseSort(filter.Sort)
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where, err := subscriptionConditions(filter, arg)
+	if err != nil {
+		return nil, searchError(err)
+	}
+
+	order, op := "ASC", ">"
+	if desc {
+		order, op = "DESC", "<"
+	}
+	if filter.Cursor != "" {
+		c, err := cursor.Decode(filter.Cursor)
+		if err != nil {
+			return nil, searchError(err)
+		}
+		value, err := CursorArg(field, c.Value)
+		if err != nil {
+			return nil, searchError(err)
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", field, op, arg(value), arg(c.Id)))
+	}
+
+	sql := "SELECT id, service_name, price, currency, billing_period, user_id, start_date, end_date, version FROM " + currentSubscriptions
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+	sql += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", field, order, order, arg(filter.Limit+1))
+
+	rows, err := s.db.Query(s.ctx, sql, args...)
+	if err != nil {
+		return nil, searchError(err)
+	}
+	defer rows.Close()
+	var subscriptions []*models.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return paginate(subscriptions, filter, field), nil
+}
+
+func (s *SubscriptionRepository) CalculateSumSubscriptions(filter *models.ReportFilter) (*models.SumSubscriptionsResponse, error) {
+	// a subscription without end_date is active through the whole window
+	with, args, err := chargesQuery(filter)
 	if err != nil {
-		return 0, fmt.Errorf("error calculating sum subscriptions: %w", err)
+		return nil, fmt.Errorf("error calculating sum subscriptions: %w", err)
+	}
+	if err := s.checkUserExists(filter.UserId); err != nil {
+		return nil, err
 	}
-	endD, err := timeparser.ParseMonthYear(endDate)
+	var sum models.SumSubscriptionsResponse
+	sum.Currency, err = s.chargesCurrency(with, args, filter)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.QueryRow(s.ctx, with+" SELECT COALESCE(SUM(amount), 0)::bigint, COUNT(*) FROM charges", args...).Scan(&sum.Sum, &sum.Months)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating sum subscriptions: %w", err)
+	}
+	return &sum, nil
+}
+
+func (s *SubscriptionRepository) MonthlyReport(filter *models.ReportFilter) (*models.MonthlyReport, error) {
+	with, args, err := chargesQuery(filter)
 	if err != nil {
-		return 0, fmt.Errorf("error calculating sum subscriptions: %w", err)
+		return nil, fmt.Errorf("error building monthly report: %w", err)
 	}
-	sql := "SELECT COALESCE(SUM(price), 0) FROM subscriptions WHERE start_date <= $1 AND end_date >= $2"
-	args := []interface{}{
-		endD.AddDate(0, 1, -1),
-		stD}
-	paramIndex := 3
-	if serviceName != "" {
-		sql += fmt.Sprintf(" AND service_name = $%d", paramIndex)
-		args = append(args, serviceName)
-		paramIndex++
+	if err := s.checkUserExists(filter.UserId); err != nil {
+		return nil, err
 	}
-	if userId != "" {
-		sql += fmt.Sprintf(" AND user_id = $%d", paramIndex)
-		args = append(args, userId)
-		exists, err := s.userExists(userId)
+	currency, err := s.chargesCurrency(with, args, filter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(s.ctx, with+`
+        SELECT month, service_name, SUM(amount)::bigint
+        FROM charges
+        GROUP BY month, service_name
+        ORDER BY month, service_name`,
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("error building monthly report: %w", err)
+	}
+	defer rows.Close()
+	var totals []serviceMonthTotal
+	for rows.Next() {
+		var total serviceMonthTotal
+		if err := rows.Scan(&total.month, &total.serviceName, &total.total); err != nil {
+			return nil, fmt.Errorf("error scanning monthly report: %w", err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return monthlyReport(filter, currency, totals)
+}
+
+func (s *SubscriptionRepository) AggregateSubscriptions(filter *models.AggregateFilter) (*models.AggregateReport, error) {
+	with, args, err := chargesQuery(&filter.ReportFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating subscriptions: %w", err)
+	}
+	grouping, ok := aggregateGroupings[filter.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("error aggregating subscriptions: unknown group_by %q", filter.GroupBy)
+	}
+	field, desc, ok := ParseAggregateOrder(filter.OrderBy)
+	if !ok {
+		return nil, fmt.Errorf("error aggregating subscriptions: unknown order_by %q", filter.OrderBy)
+	}
+	if field == "key" {
+		field = "ordinal"
+	}
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	if err := s.checkUserExists(filter.UserId); err != nil {
+		return nil, err
+	}
+	currency, err := s.chargesCurrency(with, args, &filter.ReportFilter)
+	if err != nil {
+		return nil, err
+	}
+	// a group is described by what each of its subscriptions costs within
+	// the window, so charges are summed up per subscription first
+	sql := with + fmt.Sprintf(`,
+        costs AS (
+            SELECT %s AS key, %s AS ordinal, subscription_id, SUM(amount)::bigint AS amount
+            FROM charges
+            GROUP BY 1, 2, 3
+        )
+        SELECT key, COUNT(*) AS count, SUM(amount)::bigint AS sum, ROUND(AVG(amount), 2)::float8 AS avg,
+            MIN(amount) AS min, MAX(amount) AS max
+        FROM costs
+        GROUP BY key, ordinal
+        ORDER BY %s %s, ordinal`, grouping.key, grouping.ordinal, field, order)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		sql += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	rows, err := s.db.Query(s.ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating subscriptions: %w", err)
+	}
+	defer rows.Close()
+	report := &models.AggregateReport{
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+		Currency:  currency,
+		GroupBy:   filter.GroupBy,
+		Groups:    []*models.AggregateGroup{},
+	}
+	for rows.Next() {
+		var group models.AggregateGroup
+		if err := rows.Scan(&group.Key, &group.Count, &group.Sum, &group.Avg, &group.Min, &group.Max); err != nil {
+			return nil, fmt.Errorf("error scanning aggregate group: %w", err)
+		}
+		report.Groups = append(report.Groups, &group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return report, nil
+}
+
+func (s *SubscriptionRepository) SaveExchangeRates(rates []*models.ExchangeRate) error {
+	const query = `
+        INSERT INTO exchange_rates (base_currency, quote_currency, rate, effective_from)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (base_currency, quote_currency, effective_from) DO UPDATE SET rate = EXCLUDED.rate
+    `
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		effectiveFrom, err := timeparser.ParseMonthYear(rate.EffectiveFrom)
 		if err != nil {
-			return 0, fmt.Errorf("error checking user existence: %w", err)
+			return fmt.Errorf("error saving exchange rates: %w", err)
 		}
-		if !exists {
-			return 0, suberrors.ErrUserIdNotFound
+		batch.Queue(query, rate.Base, rate.Quote, rate.Rate, effectiveFrom)
+	}
+	err := pgx.BeginFunc(s.ctx, s.db, func(tx pgx.Tx) error {
+		return tx.SendBatch(s.ctx, batch).Close()
+	})
+	if err != nil {
+		return fmt.Errorf("error saving exchange rates: %w", mapConstraintError(err))
+	}
+	return nil
+}
+
+func (s *SubscriptionRepository) ListExchangeRates() ([]*models.ExchangeRate, error) {
+	rows, err := s.db.Query(s.ctx, `
+        SELECT base_currency, quote_currency, rate::float8, effective_from
+        FROM exchange_rates
+        ORDER BY base_currency, quote_currency, effective_from`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing exchange rates: %w", err)
+	}
+	defer rows.Close()
+	rates := []*models.ExchangeRate{}
+	for rows.Next() {
+		var rate models.ExchangeRate
+		var effectiveFrom time.Time
+		if err := rows.Scan(&rate.Base, &rate.Quote, &rate.Rate, &effectiveFrom); err != nil {
+			return nil, fmt.Errorf("error scanning exchange rate: %w", err)
 		}
+		rate.EffectiveFrom = effectiveFrom.Format("01-2006")
+		rates = append(rates, &rate)
 	}
-	err = s.db.QueryRow(s.ctx, sql, args...).Scan(&sum)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return rates, nil
+}
+
+// chargesCurrency returns the currency of the charges defined by the with
+// clause of chargesQuery.
+func (s *SubscriptionRepository) chargesCurrency(with string, args []interface{}, filter *models.ReportFilter) (string, error) {
+	if filter.Currency != "" {
+		var from string
+		var month time.Time
+		err := s.db.QueryRow(s.ctx,
+			with+" SELECT source_currency, month FROM charges WHERE amount IS NULL ORDER BY month, source_currency LIMIT 1",
+			args...).Scan(&from, &month)
+		if err == nil {
+			return "", missingRateError(from, filter.Currency, month)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("error checking exchange rates: %w", err)
+		}
+	}
+	rows, err := s.db.Query(s.ctx, with+" SELECT DISTINCT currency FROM charges ORDER BY currency", args...)
 	if err != nil {
-		return 0, fmt.Errorf("error calculating sum subscriptions: %w", err)
+		return "", fmt.Errorf("error reading charges currency: %w", err)
 	}
-	return sum, nil
+	currencies, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", fmt.Errorf("error reading charges currency: %w", err)
+	}
+	return chargesCurrency(filter, currencies)
+}
+
+// checkUserExists returns suberrors.ErrUserIdNotFound for a non-empty userId
+// without subscriptions.
+func (s *SubscriptionRepository) checkUserExists(userId string) error {
+	if userId == "" {
+		return nil
+	}
+	exists, err := s.userExists(userId)
+	if err != nil {
+		return fmt.Errorf("error checking user existence: %w", err)
+	}
+	if !exists {
+		return suberrors.ErrUserIdNotFound
+	}
+	return nil
 }
 
 func (s *SubscriptionRepository) userExists(userId string) (bool, error) {
 	var exists bool
 	err := s.db.QueryRow(s.ctx,
-		"SELECT EXISTS(SELECT 1 FROM subscriptions WHERE user_id = $1)",
+		"SELECT EXISTS(SELECT 1 FROM subscriptions WHERE user_id = $1 AND deleted_at IS NULL)",
 		userId).Scan(&exists)
 	return exists, err
 }
+
+func scanSubscription(row pgx.Row) (*models.Subscription, error) {
+	var sub models.Subscription
+	var startDate time.Time
+	var endDate *time.Time
+	err := row.Scan(&sub.Id,
+		&sub.ServiceName,
+		&sub.Price.Amount,
+		&sub.Price.Currency,
+		&sub.BillingPeriod,
+		&sub.UserId,
+		&startDate,
+		&endDate,
+		&sub.Version)
+	if err != nil {
+		return nil, err
+	}
+	sub.StartDate = startDate.Format("01-2006")
+	if endDate != nil {
+		sub.EndDate = endDate.Format("01-2006")
+	}
+	return &sub, nil
+}
+
+// versionConflictError reports a failed If-Match precondition along with the
+// version the client should reload.
+func versionConflictError(current int64) error {
+	return fmt.Errorf("%w: current version is %d", suberrors.ErrVersionConflict, current)
+}
+
+// isValidId reports whether id can be stored in the uuid primary key column;
+// anything else cannot match an existing subscription.
+func isValidId(id string) bool {
+	return uuid.Validate(id) == nil
+}
+
+// mapConstraintError turns constraint violations reported by Postgres into
+// suberrors.ConstraintError and leaves other errors untouched.
+func mapConstraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return &suberrors.ConstraintError{Constraint: pgErr.ConstraintName, Err: suberrors.ErrSubscriptionConflict}
+	case pgCheckViolation:
+		return &suberrors.ConstraintError{Constraint: pgErr.ConstraintName, Err: suberrors.ErrConstraintViolation}
+	case pgInvalidTextRepresentation:
+		return &suberrors.ConstraintError{Constraint: pgErr.ColumnName, Err: suberrors.ErrConstraintViolation}
+	}
+	return err
+}
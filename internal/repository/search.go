@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/cursor"
+	"TestEffectiveMobile/pkg/timeparser"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const defaultSortField = "start_date"
+
+// sortFields are the columns subscriptions can be ordered by in SearchSubscriptions.
+var sortFields = map[string]bool{
+	"price":        true,
+	"start_date":   true,
+	"service_name": true,
+}
+
+// ParseSort splits a sort parameter such as "-price" into the field and the
+// direction; ok is false for fields that cannot be sorted by.
+func ParseSort(sort string) (field string, desc bool, ok bool) {
+	if sort == "" {
+		return defaultSortField, false, true
+	}
+	desc = strings.HasPrefix(sort, "-")
+	field = strings.TrimPrefix(sort, "-")
+	return field, desc, sortFields[field]
+}
+
+// cursorValue renders the sort field of sub the way it is kept in a cursor.
+func cursorValue(sub *models.Subscription, field string) string {
+	switch field {
+	case "price":
+		return strconv.FormatInt(sub.Price.Amount, 10)
+	case "service_name":
+		return sub.ServiceName
+	default:
+		return sub.StartDate
+	}
+}
+
+// CursorArg converts a cursor value back to the type of the sort column, it
+// fails for values that were not issued for field.
+func CursorArg(field string, value string) (interface{}, error) {
+	switch field {
+	case "price":
+		return strconv.ParseInt(value, 10, 64)
+	case "service_name":
+		return value, nil
+	default:
+		return timeparser.ParseMonthYear(value)
+	}
+}
+
+// paginate cuts a page fetched with one extra row down to filter.Limit and
+// sets the cursor of the next page if there is one.
+func paginate(subs []*models.Subscription, filter *models.SubscriptionFilter, field string) *models.ListSubscriptionsResponse {
+	page := &models.ListSubscriptionsResponse{Subscriptions: subs}
+	if len(subs) > filter.Limit {
+		page.Subscriptions = subs[:filter.Limit]
+		last := page.Subscriptions[filter.Limit-1]
+		page.NextCursor = cursor.Encode(cursor.Cursor{
+			Sort:  filter.Sort,
+			Value: cursorValue(last, field),
+			Id:    last.Id,
+		})
+	}
+	if page.Subscriptions == nil {
+		page.Subscriptions = []*models.Subscription{}
+	}
+	return page
+}
+
+func searchError(err error) error {
+	return fmt.Errorf("error searching subscriptions: %w", err)
+}
+
+// subscriptionConditions renders the conditions of filter other than the
+// cursor, arg adds a query argument and returns its placeholder.
+func subscriptionConditions(filter *models.SubscriptionFilter, arg func(value interface{}) string) ([]string, error) {
+	dateArg := func(monthYear string) (string, error) {
+		date, err := timeparser.ParseMonthYear(monthYear)
+		if err != nil {
+			return "", err
+		}
+		return arg(date), nil
+	}
+
+	var where []string
+	if filter.UserId != "" {
+		where = append(where, "user_id = "+arg(filter.UserId))
+	}
+	if filter.ServiceName != "" {
+		where = append(where, "service_name = "+arg(filter.ServiceName))
+	}
+	if filter.MinPrice != nil {
+		where = append(where, "price >= "+arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		where = append(where, "price <= "+arg(*filter.MaxPrice))
+	}
+	if filter.Currency != "" {
+		where = append(where, "currency = "+arg(filter.Currency))
+	}
+	if filter.BillingPeriod != "" {
+		where = append(where, "billing_period = "+arg(filter.BillingPeriod))
+	}
+	if filter.ActiveAt != "" {
+		date, err := dateArg(filter.ActiveAt)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, fmt.Sprintf("start_date <= %s AND (end_date IS NULL OR end_date >= %s)", date, date))
+	}
+	dateFilters := []struct {
+		value     string
+		condition string
+	}{
+		{filter.StartFrom, "start_date >= %s"},
+		{filter.StartTo, "start_date <= %s"},
+		{filter.EndFrom, "(end_date IS NULL OR end_date >= %s)"},
+		{filter.EndTo, "end_date <= %s"},
+	}
+	for _, dateFilter := range dateFilters {
+		if dateFilter.value == "" {
+			continue
+		}
+		date, err := dateArg(dateFilter.value)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, fmt.Sprintf(dateFilter.condition, date))
+	}
+	return where, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"slices"
+	"testing"
+)
+
+func searchTestRepository(t *testing.T) *MemorySubscriptionRepository {
+	return newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "alice", StartDate: "01-2025", EndDate: "06-2025"},
+		models.Subscription{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 200, Currency: "RUB"}, UserId: "alice", StartDate: "03-2025"},
+		models.Subscription{Id: "3", ServiceName: "Apple", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "bob", StartDate: "07-2025"},
+		models.Subscription{Id: "4", ServiceName: "Netflix", Price: money.Money{Amount: 100, Currency: "RUB"}, UserId: "bob", StartDate: "02-2025", EndDate: "02-2025"},
+		models.Subscription{Id: "5", ServiceName: "Apple", Price: money.Money{Amount: 300, Currency: "RUB"}, UserId: "carol", StartDate: "05-2025"},
+	)
+}
+
+// searchAll walks every page of a search and returns the ids in page order.
+func searchAll(t *testing.T, repo *MemorySubscriptionRepository, filter models.SubscriptionFilter) []string {
+	t.Helper()
+	var ids []string
+	for page := 0; ; page++ {
+		if page > 10 {
+			t.Fatalf("SearchSubscriptions(%+v) does not stop paginating", filter)
+		}
+		got, err := repo.SearchSubscriptions(&filter)
+		if err != nil {
+			t.Fatalf("SearchSubscriptions(%+v): %v", filter, err)
+		}
+		if len(got.Subscriptions) > filter.Limit {
+			t.Fatalf("SearchSubscriptions returned %d subscriptions, limit %d", len(got.Subscriptions), filter.Limit)
+		}
+		for _, sub := range got.Subscriptions {
+			ids = append(ids, sub.Id)
+		}
+		if got.NextCursor == "" {
+			return ids
+		}
+		filter.Cursor = got.NextCursor
+	}
+}
+
+func TestMemorySearchPagination(t *testing.T) {
+	repo := searchTestRepository(t)
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		// equal prices are ordered by id
+		{sort: "price", want: []string{"4", "2", "5", "1", "3"}},
+		{sort: "-price", want: []string{"3", "1", "5", "2", "4"}},
+		{sort: "start_date", want: []string{"1", "4", "2", "5", "3"}},
+		{sort: "service_name", want: []string{"3", "5", "1", "4", "2"}},
+		{sort: "-service_name", want: []string{"2", "4", "1", "5", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			for _, limit := range []int{1, 2, 5, 10} {
+				got := searchAll(t, repo, models.SubscriptionFilter{Sort: tt.sort, Limit: limit})
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("limit %d: ids = %v, want %v", limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemorySearchFilters(t *testing.T) {
+	repo := searchTestRepository(t)
+	price := func(p int64) *int64 { return &p }
+	tests := []struct {
+		name   string
+		filter models.SubscriptionFilter
+		want   []string
+	}{
+		{name: "user", filter: models.SubscriptionFilter{UserId: "alice"}, want: []string{"1", "2"}},
+		{name: "service", filter: models.SubscriptionFilter{ServiceName: "Netflix"}, want: []string{"1", "4"}},
+		{name: "price range", filter: models.SubscriptionFilter{MinPrice: price(200), MaxPrice: price(300)}, want: []string{"2", "5"}},
+		{name: "active at", filter: models.SubscriptionFilter{ActiveAt: "06-2025"}, want: []string{"1", "2", "5"}},
+		{name: "start range", filter: models.SubscriptionFilter{StartFrom: "02-2025", StartTo: "05-2025"}, want: []string{"2", "4", "5"}},
+		// open-ended subscriptions end after any date
+		{name: "end from", filter: models.SubscriptionFilter{EndFrom: "03-2025"}, want: []string{"1", "2", "3", "5"}},
+		{name: "end to", filter: models.SubscriptionFilter{EndTo: "03-2025"}, want: []string{"4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Sort = "start_date"
+			tt.filter.Limit = 10
+			got := searchAll(t, repo, tt.filter)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
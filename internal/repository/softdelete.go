@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// Delete only sets deleted_at: a deleted subscription is left out of reads,
+// lists and sums but keeps its row, price history and audit entries until it
+// is restored or purged.
+
+func (s *SubscriptionRepository) Restore(id string, meta *models.AuditMeta) (*models.Subscription, error) {
+	if !isValidId(id) {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	var restored *models.Subscription
+	err := pgx.BeginFunc(s.ctx, s.db, func(tx pgx.Tx) error {
+		res, err := tx.Exec(s.ctx,
+			"UPDATE subscriptions SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL",
+			id)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return s.notDeletedError(tx, id)
+		}
+		restored, err = scanSubscription(tx.QueryRow(s.ctx, selectSubscription, id))
+		if err != nil {
+			return err
+		}
+		after, err := snapshot(restored)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, id, models.AuditRestore, meta, nil, after)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, suberrors.ErrIdSubscriptionNotFound
+		}
+		if errors.Is(err, suberrors.ErrSubscriptionNotDeleted) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error restoring subscription: %w", err)
+	}
+	return restored, nil
+}
+
+// notDeletedError explains why Restore changed no row: the subscription id is
+// either not deleted or does not exist.
+func (s *SubscriptionRepository) notDeletedError(tx pgx.Tx, id string) error {
+	var exists bool
+	if err := tx.QueryRow(s.ctx, "SELECT EXISTS (SELECT 1 FROM subscriptions WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return pgx.ErrNoRows
+	}
+	return suberrors.ErrSubscriptionNotDeleted
+}
+
+func (s *SubscriptionRepository) Purge(deletedBefore time.Time, meta *models.AuditMeta) (int64, error) {
+	// price history goes with the subscription by ON DELETE CASCADE, the
+	// audit entries stay and get a purge entry
+	res, err := s.db.Exec(s.ctx, `
+        WITH purged AS (
+            DELETE FROM subscriptions WHERE deleted_at < $1 RETURNING id
+        )
+        INSERT INTO subscription_audit (subscription_id, action, actor, request_id)
+        SELECT id, $2, $3, $4 FROM purged`,
+		deletedBefore, models.AuditPurge, meta.Actor, meta.RequestId)
+	if err != nil {
+		return 0, fmt.Errorf("error purging subscriptions: %w", err)
+	}
+	return res.RowsAffected(), nil
+}
+
+// deletedSubscription is a row of the subscriptions table with deleted_at set.
+type deletedSubscription struct {
+	sub       models.Subscription
+	deletedAt time.Time
+}
+
+func (m *MemorySubscriptionRepository) Restore(id string, meta *models.AuditMeta) (*models.Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.subscriptions[id]; ok {
+		return nil, suberrors.ErrSubscriptionNotDeleted
+	}
+	deleted, ok := m.deleted[id]
+	if !ok {
+		return nil, suberrors.ErrIdSubscriptionNotFound
+	}
+	deleted.sub.Version++
+	restored := m.current(deleted.sub)
+	after, err := snapshot(&restored)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring subscription: %w", err)
+	}
+	delete(m.deleted, id)
+	m.subscriptions[id] = deleted.sub
+	m.order = append(m.order, id)
+	m.audit(id, models.AuditRestore, meta, nil, after)
+	return &restored, nil
+}
+
+func (m *MemorySubscriptionRepository) Purge(deletedBefore time.Time, meta *models.AuditMeta) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var purged int64
+	for id, deleted := range m.deleted {
+		if !deleted.deletedAt.Before(deletedBefore) {
+			continue
+		}
+		delete(m.deleted, id)
+		delete(m.prices, id)
+		m.audit(id, models.AuditPurge, meta, nil, nil)
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemorySoftDeleteRestore(t *testing.T) {
+	repo := newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 1000, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"},
+		models.Subscription{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 500, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"},
+	)
+	change := &models.PriceChange{Price: money.Money{Amount: 2000, Currency: "RUB"}, EffectiveFrom: "03-2025"}
+	if err := repo.SchedulePriceChange("1", change, testMeta); err != nil {
+		t.Fatalf("SchedulePriceChange: %v", err)
+	}
+	window := &models.ReportFilter{StartDate: "01-2025", EndDate: "03-2025"}
+
+	if err := repo.Delete("1", nil, testMeta); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Delete("1", nil, testMeta); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("second Delete error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+	if _, err := repo.Read("1"); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("Read of a deleted subscription error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+	subs, err := repo.ListSubscriptions("user123")
+	if err != nil || len(subs) != 1 || subs[0].Id != "2" {
+		t.Errorf("ListSubscriptions = %v, %v, want only 2", subs, err)
+	}
+	found, err := repo.SearchSubscriptions(&models.SubscriptionFilter{Limit: 10})
+	if err != nil || len(found.Subscriptions) != 1 {
+		t.Errorf("SearchSubscriptions = %+v, %v, want only 2", found, err)
+	}
+	sum, err := repo.CalculateSumSubscriptions(window)
+	if err != nil || sum.Sum != 3*500 {
+		t.Errorf("CalculateSumSubscriptions = %+v, %v, want %d", sum, err, 3*500)
+	}
+
+	restored, err := repo.Restore("1", testMeta)
+	if err != nil || restored.Id != "1" || restored.ServiceName != "Netflix" {
+		t.Fatalf("Restore = %+v, %v", restored, err)
+	}
+	// the price history comes back with the subscription
+	sum, err = repo.CalculateSumSubscriptions(window)
+	if err != nil || sum.Sum != 2*1000+2000+3*500 {
+		t.Errorf("CalculateSumSubscriptions after Restore = %+v, %v, want %d", sum, err, 2*1000+2000+3*500)
+	}
+	if _, err := repo.Restore("1", testMeta); !errors.Is(err, suberrors.ErrSubscriptionNotDeleted) {
+		t.Errorf("Restore of an active subscription error = %v, want ErrSubscriptionNotDeleted", err)
+	}
+	if _, err := repo.Restore("3", testMeta); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("Restore of an unknown subscription error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+}
+
+func TestMemoryPurge(t *testing.T) {
+	repo := newTestRepository(t,
+		models.Subscription{Id: "1", ServiceName: "Netflix", Price: money.Money{Amount: 1000, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"},
+		models.Subscription{Id: "2", ServiceName: "Spotify", Price: money.Money{Amount: 500, Currency: "RUB"}, UserId: "user123", StartDate: "01-2025"},
+	)
+	if err := repo.Delete("1", nil, testMeta); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// subscriptions deleted within the retention are kept
+	purged, err := repo.Purge(time.Now().Add(-time.Hour), testMeta)
+	if err != nil || purged != 0 {
+		t.Fatalf("Purge within retention = %d, %v, want 0", purged, err)
+	}
+	purged, err = repo.Purge(time.Now().Add(time.Hour), testMeta)
+	if err != nil || purged != 1 {
+		t.Fatalf("Purge = %d, %v, want 1", purged, err)
+	}
+	if _, err := repo.Restore("1", testMeta); !errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
+		t.Errorf("Restore of a purged subscription error = %v, want ErrIdSubscriptionNotFound", err)
+	}
+	// active subscriptions are never purged
+	if _, err := repo.Read("2"); err != nil {
+		t.Errorf("Read of an active subscription after Purge: %v", err)
+	}
+	entries, err := repo.History("1")
+	if err != nil || entries[len(entries)-1].Action != models.AuditPurge {
+		t.Errorf("History after Purge = %v, %v, want a purge entry last", entries, err)
+	}
+}
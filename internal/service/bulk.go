@@ -0,0 +1,114 @@
+package service
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/suberrors"
+	"go.uber.org/zap"
+)
+
+// The bulk methods return the error of every item in the order of the
+// request, an error is returned only if the batch could not run at all.
+
+// BulkCreate creates subs like Create in the given mode.
+func (s *SubscriptionService) BulkCreate(subs []*models.Subscription, mode string, meta *models.AuditMeta) ([]error, error) {
+	if err := validateBulk(mode, len(subs)); err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(subs))
+	for i, sub := range subs {
+		errs[i] = prepareCreate(sub)
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Bulk create", zap.String("mode", mode), zap.Int("items", len(subs)))
+	return applyBulk(mode, errs, func(valid []int) ([]error, error) {
+		selected := make([]*models.Subscription, len(valid))
+		for j, i := range valid {
+			selected[j] = subs[i]
+		}
+		return s.Repository.BulkCreate(selected, mode == models.BulkAtomic, meta)
+	})
+}
+
+// BulkUpdate patches subscriptions like Patch in the given mode and returns
+// the updated subscriptions.
+func (s *SubscriptionService) BulkUpdate(items []*models.BulkUpdateItem, mode string, meta *models.AuditMeta) ([]*models.Subscription, []error, error) {
+	if err := validateBulk(mode, len(items)); err != nil {
+		return nil, nil, err
+	}
+	errs := make([]error, len(items))
+	for i, item := range items {
+		if errs[i] = s.checkPrecondition(item.Match()); errs[i] == nil {
+			errs[i] = s.validatePatch(item.Id, item.Update())
+		}
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Bulk update", zap.String("mode", mode), zap.Int("items", len(items)))
+	updated := make([]*models.Subscription, len(items))
+	errs, err := applyBulk(mode, errs, func(valid []int) ([]error, error) {
+		selected := make([]*models.BulkUpdateItem, len(valid))
+		for j, i := range valid {
+			selected[j] = items[i]
+		}
+		subs, itemErrs, err := s.Repository.BulkUpdate(selected, mode == models.BulkAtomic, meta)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range valid {
+			updated[i] = subs[j]
+		}
+		return itemErrs, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, errs, nil
+}
+
+// BulkDelete deletes subscriptions like Delete in the given mode.
+func (s *SubscriptionService) BulkDelete(items []*models.BulkDeleteItem, mode string, meta *models.AuditMeta) ([]error, error) {
+	if err := validateBulk(mode, len(items)); err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(items))
+	for i, item := range items {
+		if errs[i] = s.checkPrecondition(item.Match()); errs[i] == nil {
+			errs[i] = validateId(item.Id)
+		}
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Bulk delete", zap.String("mode", mode), zap.Int("items", len(items)))
+	return applyBulk(mode, errs, func(valid []int) ([]error, error) {
+		selected := make([]*models.BulkDeleteItem, len(valid))
+		for j, i := range valid {
+			selected[j] = items[i]
+		}
+		return s.Repository.BulkDelete(selected, mode == models.BulkAtomic, meta)
+	})
+}
+
+// applyBulk passes the indexes of the items without a validation error to
+// apply and merges its errors into errs. An atomic batch with an invalid item
+// is not applied at all.
+func applyBulk(mode string, errs []error, apply func(valid []int) ([]error, error)) ([]error, error) {
+	var valid []int
+	for i, err := range errs {
+		if err == nil {
+			valid = append(valid, i)
+		}
+	}
+	if mode == models.BulkAtomic && len(valid) < len(errs) {
+		for _, i := range valid {
+			errs[i] = suberrors.ErrBulkAborted
+		}
+		return errs, nil
+	}
+	if len(valid) == 0 {
+		return errs, nil
+	}
+	applied, err := apply(valid)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range valid {
+		errs[i] = applied[j]
+	}
+	return errs, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"slices"
+	"strings"
+)
+
+// maxImportRows limits the rows of a CSV import.
+const maxImportRows = 10000
+
+// importColumns are the required columns of a CSV import, the header names
+// them in any order along with the optional currency, billing_period and
+// end_date.
+var importColumns = []string{"service_name", "price", "user_id", "start_date"}
+
+// importFields renames the fields of validation errors to the CSV columns.
+var importFields = map[string]string{"price.amount": "price", "price.currency": "currency"}
+
+// ImportSubscriptions reads subscriptions from CSV with a header row and
+// validates every row like Create. Unless dryRun, the valid rows are created
+// in one batch, invalid rows are skipped and reported.
+func (s *SubscriptionService) ImportSubscriptions(r io.Reader, dryRun bool, meta *models.AuditMeta) (*models.ImportReport, error) {
+	rows, err := readImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.ImportReport{DryRun: dryRun, Rows: len(rows)}
+	var subs []*models.Subscription
+	for _, row := range rows {
+		fields := row.fields
+		var verr *suberrors.ValidationError
+		if errors.As(prepareCreate(row.sub), &verr) {
+			for _, field := range verr.Fields {
+				field = importField(field)
+				// a cell that could not be parsed is reported once
+				if !slices.ContainsFunc(fields, func(parsed suberrors.FieldError) bool { return parsed.Field == field.Field }) {
+					fields = append(fields, field)
+				}
+			}
+		}
+		if len(fields) > 0 {
+			report.Errors = append(report.Errors, models.ImportRowError{Line: row.line, Errors: fields})
+			continue
+		}
+		subs = append(subs, row.sub)
+	}
+	report.Valid, report.Failed = len(subs), len(report.Errors)
+	logger.GetLoggerFromCtx(s.ctx).Info("Import subscriptions", zap.Bool("dry_run", dryRun),
+		zap.Int("rows", report.Rows), zap.Int("valid", report.Valid))
+	if dryRun || len(subs) == 0 {
+		return report, nil
+	}
+	errs, err := s.Repository.BulkCreate(subs, true, meta)
+	if err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		// the valid rows are created all together, a row rejected by the
+		// storage fails the whole import
+		if err != nil && !errors.Is(err, suberrors.ErrBulkAborted) {
+			return nil, err
+		}
+	}
+	report.Imported = len(subs)
+	return report, nil
+}
+
+// importRow is a data row of a CSV import with the errors of the cells that
+// could not be parsed.
+type importRow struct {
+	line   int
+	sub    *models.Subscription
+	fields []suberrors.FieldError
+}
+
+// importField renames the field of a validation error to its CSV column.
+func importField(field suberrors.FieldError) suberrors.FieldError {
+	if column, ok := importFields[field.Field]; ok {
+		field.Message = strings.Replace(field.Message, field.Field, column, 1)
+		field.Field = column
+	}
+	return field
+}
+
+// readImportCSV reads the data rows of a CSV import, a malformed file is a
+// validation error of the whole import.
+func readImportCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	verr := &suberrors.ValidationError{}
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		verr.Add("file", suberrors.CodeRequired, "file must contain a header row")
+		return nil, verr
+	}
+	if err != nil {
+		verr.Add("file", suberrors.CodeInvalidFormat, "file is not valid CSV")
+		return nil, verr
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			verr.Add("header", suberrors.CodeRequired, "header must contain "+strings.Join(importColumns, ","))
+			return nil, verr
+		}
+	}
+	// rows may be shorter or longer than the header, missing cells are empty
+	reader.FieldsPerRecord = -1
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			verr.Add("file", suberrors.CodeInvalidFormat, err.Error())
+			return nil, verr
+		}
+		if len(rows) == maxImportRows {
+			verr.Add("file", suberrors.CodeOutOfRange, fmt.Sprintf("file must contain at most %d rows", maxImportRows))
+			return nil, verr
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseImportRow(line, columns, record))
+	}
+	if len(rows) == 0 {
+		verr.Add("file", suberrors.CodeRequired, "file must contain at least one row")
+		return nil, verr
+	}
+	return rows, nil
+}
+
+func parseImportRow(line int, columns map[string]int, record []string) importRow {
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	row := importRow{line: line, sub: &models.Subscription{
+		ServiceName:   cell("service_name"),
+		Price:         money.Money{Currency: cell("currency")},
+		BillingPeriod: cell("billing_period"),
+		UserId:        cell("user_id"),
+		StartDate:     cell("start_date"),
+		EndDate:       cell("end_date"),
+	}}
+	if price := cell("price"); price != "" {
+		amount, ok := money.ParseAmount(price)
+		if !ok {
+			row.fields = append(row.fields, suberrors.FieldError{
+				Field:   "price",
+				Code:    suberrors.CodeInvalidType,
+				Message: "price must be an amount such as 399.99",
+			})
+		}
+		row.sub.Price.Amount = amount
+	}
+	return row
+}
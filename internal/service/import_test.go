@@ -0,0 +1,88 @@
+package service
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const importCSV = `user_id,service_name,price,currency,start_date,end_date
+60601fee-2bf1-4721-ae6f-7636e79a0cba,Netflix,399.99,USD,01-2025,
+60601fee-2bf1-4721-ae6f-7636e79a0cba,Spotify,abc,,01-2025,
+60601fee-2bf1-4721-ae6f-7636e79a0cba, Yandex Plus ,299,,02-2025,01-2025
+60601fee-2bf1-4721-ae6f-7636e79a0cba,Apple,1,xyz,01-2025
+`
+
+func TestImportSubscriptions(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		s, repo := newTestService(t)
+		report, err := s.ImportSubscriptions(strings.NewReader(importCSV), dryRun, &models.AuditMeta{})
+		if err != nil {
+			t.Fatalf("dry run %v: ImportSubscriptions: %v", dryRun, err)
+		}
+		imported := 1
+		if dryRun {
+			imported = 0
+		}
+		if report.Rows != 4 || report.Valid != 1 || report.Failed != 3 || report.Imported != imported {
+			t.Errorf("dry run %v: report = %+v", dryRun, report)
+		}
+		var lines []int
+		fields := make(map[int][]string)
+		for _, row := range report.Errors {
+			lines = append(lines, row.Line)
+			for _, field := range row.Errors {
+				fields[row.Line] = append(fields[row.Line], field.Field)
+			}
+		}
+		if want := []int{3, 4, 5}; !reflect.DeepEqual(lines, want) {
+			t.Errorf("dry run %v: error lines = %v, want %v", dryRun, lines, want)
+		}
+		if want := []string{"price"}; !reflect.DeepEqual(fields[3], want) {
+			t.Errorf("dry run %v: line 3 fields = %v, want %v", dryRun, fields[3], want)
+		}
+		if want := []string{"currency"}; !reflect.DeepEqual(fields[5], want) {
+			t.Errorf("dry run %v: line 5 fields = %v, want %v", dryRun, fields[5], want)
+		}
+
+		if len(repo.created) != imported {
+			t.Fatalf("dry run %v: created %d subscriptions, want %d", dryRun, len(repo.created), imported)
+		}
+		if !dryRun {
+			sub := repo.created[0]
+			if sub.ServiceName != "Netflix" || sub.Price != (money.Money{Amount: 39999, Currency: "USD"}) || sub.BillingPeriod != models.BillingMonthly {
+				t.Errorf("created %+v", sub)
+			}
+		}
+	}
+}
+
+func TestImportSubscriptionsFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		field string
+	}{
+		{name: "empty", input: "", field: "file"},
+		{name: "no rows", input: "service_name,price,user_id,start_date\n", field: "file"},
+		{name: "missing column", input: "service_name,user_id,start_date\nNetflix,user123,01-2025\n", field: "header"},
+		{name: "bad quoting", input: "service_name,price,user_id,start_date\n\"Netflix,1,user123,01-2025\n", field: "file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			_, err := s.ImportSubscriptions(strings.NewReader(tt.input), false, &models.AuditMeta{})
+			var verr *suberrors.ValidationError
+			if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != tt.field {
+				t.Fatalf("ImportSubscriptions error = %v, want a validation error of %q", err, tt.field)
+			}
+			if len(repo.created) != 0 {
+				t.Errorf("created %+v", repo.created)
+			}
+		})
+	}
+}
@@ -5,19 +5,45 @@ import (
 	"TestEffectiveMobile/internal/models"
 	"TestEffectiveMobile/internal/repository"
 	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"TestEffectiveMobile/pkg/timeparser"
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
 	"regexp"
+	"time"
 )
 
 type SubscriptionServiceInterface interface {
-	Create(sub *models.Subscription) (string, error)
+	Create(sub *models.Subscription, meta *models.AuditMeta) (string, error)
 	Read(id string) (*models.Subscription, error)
-	Update(id string, sub *models.UpdateSubscription) error
-	Delete(id string) error
+	Update(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error)
+	Patch(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error)
+	Delete(id string, match *models.VersionMatch, meta *models.AuditMeta) error
 	ListSubscriptions(userId string) ([]*models.Subscription, error)
-	CalculateSumSubscriptions(userId string, startDate string, endDate string, serviceName string) (int, error)
+	SearchSubscriptions(filter *models.SubscriptionFilter) (*models.ListSubscriptionsResponse, error)
+	CalculateSumSubscriptions(filter *models.ReportFilter) (*models.SumSubscriptionsResponse, error)
+	MonthlyReport(filter *models.ReportFilter) (*models.MonthlyReport, error)
+	AggregateSubscriptions(filter *models.AggregateFilter) (*models.AggregateReport, error)
+	SaveExchangeRates(rates []*models.ExchangeRate) error
+	ListExchangeRates() ([]*models.ExchangeRate, error)
+	SchedulePriceChange(id string, change *models.PriceChange, meta *models.AuditMeta) (*models.PriceChange, error)
+	ListPrices(id string) ([]*models.PriceChange, error)
+	History(id string) ([]*models.AuditEntry, error)
+	Restore(id string, meta *models.AuditMeta) (*models.Subscription, error)
+	Purge(retention time.Duration, meta *models.AuditMeta) (int64, error)
+	BeginIdempotentRequest(scope string, key string, requestHash string) (*models.IdempotencyRecord, error)
+	CompleteIdempotentRequest(record *models.IdempotencyRecord) error
+	AbandonIdempotentRequest(scope string, key string) error
+	PurgeIdempotencyKeys() (int64, error)
+	BulkCreate(subs []*models.Subscription, mode string, meta *models.AuditMeta) ([]error, error)
+	BulkUpdate(items []*models.BulkUpdateItem, mode string, meta *models.AuditMeta) ([]*models.Subscription, []error, error)
+	BulkDelete(items []*models.BulkDeleteItem, mode string, meta *models.AuditMeta) ([]error, error)
+	ImportSubscriptions(r io.Reader, dryRun bool, meta *models.AuditMeta) (*models.ImportReport, error)
+	ExportSubscriptions(filter *models.SubscriptionFilter, visit func(sub *models.Subscription) error) error
 }
 
 type SubscriptionService struct {
@@ -34,65 +60,349 @@ func NewSubscriptionService(repo repository.SubscriptionRepositoryInterface, cfg
 	}
 }
 
-func (s *SubscriptionService) Create(sub *models.Subscription) (string, error) {
-	if sub == nil || sub.ServiceName == "" || sub.Price == 0 || sub.UserId == "" || sub.StartDate == "" || sub.EndDate == "" {
-		return "", fmt.Errorf("sub is empty")
+func (s *SubscriptionService) Create(sub *models.Subscription, meta *models.AuditMeta) (string, error) {
+	if err := prepareCreate(sub); err != nil {
+		return "", err
 	}
-	if !IsValidMMYYYY(sub.StartDate) || !IsValidMMYYYY(sub.EndDate) {
-		return "", fmt.Errorf("startDate or endDate is not valid")
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Create sub: %v", sub))
+	return sub.Id, s.Repository.Create(sub, meta)
+}
+
+// prepareCreate validates a new subscription, fills in the defaults and
+// assigns its id.
+func prepareCreate(sub *models.Subscription) error {
+	verr := &suberrors.ValidationError{}
+	if sub == nil {
+		verr.Add("body", suberrors.CodeRequired, "subscription is required")
+		return verr
+	}
+	validateRequired(verr, "service_name", sub.ServiceName)
+	validatePrice(verr, sub.Price)
+	validateOneOf(verr, "billing_period", sub.BillingPeriod, billingPeriods...)
+	validateRequired(verr, "user_id", sub.UserId)
+	validatePeriod(verr, sub.StartDate, sub.EndDate)
+	if err := verr.OrNil(); err != nil {
+		return err
+	}
+	if sub.Price.Currency == "" {
+		sub.Price.Currency = money.DefaultCurrency
+	}
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = models.BillingMonthly
 	}
 	sub.Id = uuid.New().String()
-	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Create sub: %v", sub))
-	return sub.Id, s.Repository.Create(sub)
+	sub.Version = 1
+	return nil
 }
 
 func (s *SubscriptionService) Read(id string) (*models.Subscription, error) {
-	if id == "" {
-		return nil, fmt.Errorf("id is empty")
+	if err := validateId(id); err != nil {
+		return nil, err
 	}
 	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Read id: %s", id))
 	return s.Repository.Read(id)
 }
 
-func (s *SubscriptionService) Update(id string, sub *models.UpdateSubscription) error {
-	if id == "" || sub == nil || sub.ServiceName == "" || sub.Price == 0 || sub.StartDate == "" || sub.EndDate == "" {
-		return fmt.Errorf("sub or id is empty")
+func (s *SubscriptionService) Update(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error) {
+	if err := s.checkPrecondition(match); err != nil {
+		return nil, err
+	}
+	verr := &suberrors.ValidationError{}
+	validateRequired(verr, "id", id)
+	if sub == nil {
+		verr.Add("body", suberrors.CodeRequired, "subscription is required")
+		return nil, verr
+	}
+	if sub.EndDate == nil {
+		// a full update without end_date makes the subscription open-ended
+		sub.EndDate = new(string)
+	}
+	if sub.BillingPeriod == nil {
+		billingPeriod := models.BillingMonthly
+		sub.BillingPeriod = &billingPeriod
+	}
+	validateRequired(verr, "service_name", valueOf(sub.ServiceName))
+	validatePrice(verr, valueOf(sub.Price))
+	validateOneOf(verr, "billing_period", *sub.BillingPeriod, billingPeriods...)
+	validatePeriod(verr, valueOf(sub.StartDate), *sub.EndDate)
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+	// a price without currency keeps the currency of the subscription, the
+	// repository fills it in
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Update id: %s", id), zap.Any("sub", sub))
+	return s.Repository.Update(id, sub, match, meta)
+}
+
+func (s *SubscriptionService) Patch(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error) {
+	if err := s.checkPrecondition(match); err != nil {
+		return nil, err
+	}
+	if err := s.validatePatch(id, sub); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Patch id: %s", id), zap.Any("sub", sub))
+	return s.Repository.Update(id, sub, match, meta)
+}
+
+// validatePatch validates the fields of a partial update, a new period is
+// checked against the stored one.
+func (s *SubscriptionService) validatePatch(id string, sub *models.UpdateSubscription) error {
+	verr := &suberrors.ValidationError{}
+	validateRequired(verr, "id", id)
+	if sub == nil || (sub.ServiceName == nil && sub.Price == nil && sub.BillingPeriod == nil && sub.StartDate == nil && sub.EndDate == nil) {
+		verr.Add("body", suberrors.CodeRequired, "at least one field to update is required")
+		return verr
+	}
+	if sub.ServiceName != nil {
+		validateRequired(verr, "service_name", *sub.ServiceName)
+	}
+	if sub.Price != nil {
+		validatePrice(verr, *sub.Price)
 	}
-	if !IsValidMMYYYY(sub.StartDate) || !IsValidMMYYYY(sub.EndDate) {
-		return fmt.Errorf("startDate or endDate is not valid")
+	if sub.BillingPeriod != nil {
+		validateRequired(verr, "billing_period", *sub.BillingPeriod)
+		validateOneOf(verr, "billing_period", *sub.BillingPeriod, billingPeriods...)
 	}
-	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Update id: %s, sub: %v", id, sub))
-	return s.Repository.Update(id, sub)
+	if sub.StartDate != nil {
+		validateRequired(verr, "start_date", *sub.StartDate)
+		validateMonthYear(verr, "start_date", *sub.StartDate)
+	}
+	if sub.EndDate != nil {
+		validateMonthYear(verr, "end_date", *sub.EndDate)
+	}
+	if err := verr.OrNil(); err != nil {
+		return err
+	}
+	if sub.StartDate != nil || sub.EndDate != nil {
+		current, err := s.Repository.Read(id)
+		if err != nil {
+			return err
+		}
+		startDate, endDate := current.StartDate, current.EndDate
+		if sub.StartDate != nil {
+			startDate = *sub.StartDate
+		}
+		if sub.EndDate != nil {
+			endDate = *sub.EndDate
+		}
+		validatePeriod(verr, startDate, endDate)
+	}
+	return verr.OrNil()
 }
 
-func (s *SubscriptionService) Delete(id string) error {
-	if id == "" {
-		return fmt.Errorf("id is empty")
+func (s *SubscriptionService) Delete(id string, match *models.VersionMatch, meta *models.AuditMeta) error {
+	if err := s.checkPrecondition(match); err != nil {
+		return err
+	}
+	if err := validateId(id); err != nil {
+		return err
 	}
 	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Delete id: %s", id))
-	return s.Repository.Delete(id)
+	return s.Repository.Delete(id, match, meta)
 }
 
 func (s *SubscriptionService) ListSubscriptions(userId string) ([]*models.Subscription, error) {
-	if userId == "" {
-		return nil, fmt.Errorf("user_id is empty")
+	verr := &suberrors.ValidationError{}
+	validateRequired(verr, "user_id", userId)
+	if err := verr.OrNil(); err != nil {
+		return nil, err
 	}
 	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("List user_id: %s", userId))
 	return s.Repository.ListSubscriptions(userId)
 }
 
-func (s *SubscriptionService) CalculateSumSubscriptions(userId string, startDate string, endDate string, serviceName string) (int, error) {
-	if startDate == "" || endDate == "" {
-		return 0, fmt.Errorf("userId or startDate or endDate or serviceName is empty")
+func (s *SubscriptionService) SearchSubscriptions(filter *models.SubscriptionFilter) (*models.ListSubscriptionsResponse, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Search subscriptions", zap.Any("filter", filter))
+	return s.Repository.SearchSubscriptions(filter)
+}
+
+// ExportSubscriptions passes every subscription matching filter to visit
+// without paging, visit errors stop the export and are returned as is.
+func (s *SubscriptionService) ExportSubscriptions(filter *models.SubscriptionFilter, visit func(sub *models.Subscription) error) error {
+	if err := validateExportFilter(filter); err != nil {
+		return err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Export subscriptions", zap.Any("filter", filter))
+	return s.Repository.ExportSubscriptions(filter, visit)
+}
+
+func (s *SubscriptionService) CalculateSumSubscriptions(filter *models.ReportFilter) (*models.SumSubscriptionsResponse, error) {
+	if err := validateReportFilter(filter); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Calculate Sum userId: %s, startDate: %s, endDate: %s, serviceName: %s, currency: %s, mode: %s",
+		filter.UserId, filter.StartDate, filter.EndDate, filter.ServiceName, filter.Currency, filter.Mode))
+	return s.Repository.CalculateSumSubscriptions(filter)
+}
+
+func (s *SubscriptionService) MonthlyReport(filter *models.ReportFilter) (*models.MonthlyReport, error) {
+	if err := validateReportFilter(filter); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Monthly report", zap.Any("filter", filter))
+	return s.Repository.MonthlyReport(filter)
+}
+
+func (s *SubscriptionService) AggregateSubscriptions(filter *models.AggregateFilter) (*models.AggregateReport, error) {
+	if err := validateAggregateFilter(filter); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Aggregate subscriptions", zap.Any("filter", filter))
+	return s.Repository.AggregateSubscriptions(filter)
+}
+
+func (s *SubscriptionService) SaveExchangeRates(rates []*models.ExchangeRate) error {
+	if err := validateExchangeRates(rates); err != nil {
+		return err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Save exchange rates: %d", len(rates)))
+	return s.Repository.SaveExchangeRates(rates)
+}
+
+func (s *SubscriptionService) ListExchangeRates() ([]*models.ExchangeRate, error) {
+	logger.GetLoggerFromCtx(s.ctx).Info("List exchange rates")
+	return s.Repository.ListExchangeRates()
+}
+
+func (s *SubscriptionService) SchedulePriceChange(id string, change *models.PriceChange, meta *models.AuditMeta) (*models.PriceChange, error) {
+	verr := &suberrors.ValidationError{}
+	validateRequired(verr, "id", id)
+	if change == nil {
+		verr.Add("body", suberrors.CodeRequired, "price change is required")
+		return nil, verr
+	}
+	validatePrice(verr, change.Price)
+	validateRequired(verr, "effective_from", change.EffectiveFrom)
+	validateMonthYear(verr, "effective_from", change.EffectiveFrom)
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+	current, err := s.Repository.Read(id)
+	if err != nil {
+		return nil, err
+	}
+	validatePriceChange(verr, change, current)
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+	if change.Price.Currency == "" {
+		change.Price.Currency = current.Price.Currency
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Schedule price change id: %s", id), zap.Any("change", change))
+	return change, s.Repository.SchedulePriceChange(id, change, meta)
+}
+
+func (s *SubscriptionService) ListPrices(id string) ([]*models.PriceChange, error) {
+	if err := validateId(id); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("List prices id: %s", id))
+	return s.Repository.ListPrices(id)
+}
+
+func (s *SubscriptionService) History(id string) ([]*models.AuditEntry, error) {
+	if err := validateId(id); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("History id: %s", id))
+	return s.Repository.History(id)
+}
+
+func (s *SubscriptionService) Restore(id string, meta *models.AuditMeta) (*models.Subscription, error) {
+	if err := validateId(id); err != nil {
+		return nil, err
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Restore id: %s", id))
+	return s.Repository.Restore(id, meta)
+}
+
+// Purge hard-deletes subscriptions deleted more than retention ago.
+func (s *SubscriptionService) Purge(retention time.Duration, meta *models.AuditMeta) (int64, error) {
+	if retention < 0 {
+		verr := &suberrors.ValidationError{}
+		verr.Add("retention", suberrors.CodeOutOfRange, "retention must not be negative")
+		return 0, verr
+	}
+	deletedBefore := time.Now().Add(-retention)
+	logger.GetLoggerFromCtx(s.ctx).Info("Purge deleted subscriptions", zap.Time("deleted_before", deletedBefore))
+	return s.Repository.Purge(deletedBefore, meta)
+}
+
+// BeginIdempotentRequest reserves an idempotency key for a request with the
+// given hash for IDEMPOTENCY_TTL. It returns the saved response of an earlier
+// identical request to replay or nil when the request should be handled.
+func (s *SubscriptionService) BeginIdempotentRequest(scope string, key string, requestHash string) (*models.IdempotencyRecord, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength || !isPrintableASCII(key) {
+		verr := &suberrors.ValidationError{}
+		verr.Add("Idempotency-Key", suberrors.CodeInvalidFormat,
+			fmt.Sprintf("idempotency key must be 1 to %d printable ASCII characters", maxIdempotencyKeyLength))
+		return nil, verr
 	}
-	if !IsValidMMYYYY(startDate) || !IsValidMMYYYY(endDate) {
-		return 0, fmt.Errorf("startDate or endDate is not valid")
+	existing, err := s.Repository.ReserveIdempotencyKey(&models.IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(s.cfg.IdempotencyTTL),
+	})
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if existing.RequestHash != requestHash {
+		return nil, suberrors.ErrIdempotencyKeyReused
+	}
+	if existing.Status == 0 {
+		return nil, suberrors.ErrIdempotencyInProgress
+	}
+	logger.GetLoggerFromCtx(s.ctx).Info("Replay idempotent request", zap.String("scope", scope), zap.String("key", key))
+	return existing, nil
+}
+
+// CompleteIdempotentRequest saves the response of a request reserved by
+// BeginIdempotentRequest for its retries.
+func (s *SubscriptionService) CompleteIdempotentRequest(record *models.IdempotencyRecord) error {
+	return s.Repository.SaveIdempotentResponse(record)
+}
+
+// AbandonIdempotentRequest frees a key whose request has no response worth
+// replaying, so that it can be retried.
+func (s *SubscriptionService) AbandonIdempotentRequest(scope string, key string) error {
+	return s.Repository.ReleaseIdempotencyKey(scope, key)
+}
+
+// PurgeIdempotencyKeys removes expired idempotency keys.
+func (s *SubscriptionService) PurgeIdempotencyKeys() (int64, error) {
+	return s.Repository.PurgeIdempotencyKeys()
+}
+
+// checkPrecondition requires an If-Match precondition on writes in strict
+// mode, otherwise a write without one overwrites whatever version is stored.
+func (s *SubscriptionService) checkPrecondition(match *models.VersionMatch) error {
+	if match == nil && s.cfg.RequireIfMatch {
+		return suberrors.ErrPreconditionRequired
 	}
-	logger.GetLoggerFromCtx(s.ctx).Info(fmt.Sprintf("Calculate Sum userId: %s, startDate: %s, endDate: %s, serviceName: %s", userId, startDate, endDate, serviceName))
-	return s.Repository.CalculateSumSubscriptions(userId, startDate, endDate, serviceName)
+	return nil
 }
 
 func IsValidMMYYYY(dateStr string) bool {
 	matched, _ := regexp.MatchString(`^(0[1-9]|1[0-2])-20[0-9][0-9]$`, dateStr)
 	return matched
 }
+
+func IsOrderedMMYYYY(startDate string, endDate string) bool {
+	stD, err := timeparser.ParseMonthYear(startDate)
+	if err != nil {
+		return false
+	}
+	endD, err := timeparser.ParseMonthYear(endDate)
+	if err != nil {
+		return false
+	}
+	return !stD.After(endD)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/money"
+	"context"
+	"testing"
+)
+
+// stubRepository records the subscriptions it is asked to create and the
+// updates it is asked to apply, the embedded interface panics on any other call.
+type stubRepository struct {
+	repository.SubscriptionRepositoryInterface
+	created []*models.Subscription
+	stored  *models.Subscription
+	updates []*models.UpdateSubscription
+}
+
+func (r *stubRepository) Create(sub *models.Subscription, meta *models.AuditMeta) error {
+	r.created = append(r.created, sub)
+	return nil
+}
+
+func (r *stubRepository) BulkCreate(subs []*models.Subscription, atomic bool, meta *models.AuditMeta) ([]error, error) {
+	r.created = append(r.created, subs...)
+	return make([]error, len(subs)), nil
+}
+
+func (r *stubRepository) Read(id string) (*models.Subscription, error) {
+	return r.stored, nil
+}
+
+func (r *stubRepository) Update(id string, sub *models.UpdateSubscription, match *models.VersionMatch, meta *models.AuditMeta) (*models.Subscription, error) {
+	r.updates = append(r.updates, sub)
+	return r.stored, nil
+}
+
+func newTestService(t *testing.T) (*SubscriptionService, *stubRepository) {
+	t.Helper()
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	repo := &stubRepository{}
+	return NewSubscriptionService(repo, &config.Config{}, ctx), repo
+}
+
+func TestCreateOpenEnded(t *testing.T) {
+	s, repo := newTestService(t)
+	id, err := s.Create(&models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025"}, &models.AuditMeta{})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if id == "" || len(repo.created) != 1 || repo.created[0].EndDate != "" {
+		t.Errorf("Create = %q, created %+v", id, repo.created)
+	}
+}
+
+func TestCreateDefaultCurrency(t *testing.T) {
+	s, repo := newTestService(t)
+	if _, err := s.Create(&models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: 40000}, UserId: "user123", StartDate: "07-2025"}, &models.AuditMeta{}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if want := (money.Money{Amount: 40000, Currency: money.DefaultCurrency}); len(repo.created) != 1 || repo.created[0].Price != want {
+		t.Errorf("created %+v, want price %+v", repo.created, want)
+	}
+}
+
+func TestCreateInvalidDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+	}{
+		{name: "end before start", startDate: "07-2025", endDate: "06-2025"},
+		{name: "bad end", startDate: "07-2025", endDate: "2025-08"},
+		{name: "bad start", startDate: "7-2025"},
+		{name: "no start", startDate: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			_, err := s.Create(&models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: tt.startDate, EndDate: tt.endDate}, &models.AuditMeta{})
+			if err == nil || len(repo.created) != 0 {
+				t.Errorf("Create(%q, %q) error = %v, created %d", tt.startDate, tt.endDate, err, len(repo.created))
+			}
+		})
+	}
+}
+
+func TestIsOrderedMMYYYY(t *testing.T) {
+	tests := []struct {
+		start string
+		end   string
+		want  bool
+	}{
+		{start: "07-2025", end: "07-2025", want: true},
+		{start: "12-2024", end: "01-2025", want: true},
+		{start: "02-2025", end: "01-2025", want: false},
+		{start: "02-2025", end: "bad", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsOrderedMMYYYY(tt.start, tt.end); got != tt.want {
+			t.Errorf("IsOrderedMMYYYY(%q, %q) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestPatch(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	price := money.Money{Amount: 500}
+	zero := money.Money{Currency: "RUB"}
+	tests := []struct {
+		name  string
+		patch models.UpdateSubscription
+		ok    bool
+	}{
+		{name: "price only", patch: models.UpdateSubscription{Price: &price}, ok: true},
+		{name: "end date after the stored start", patch: models.UpdateSubscription{EndDate: ptr("12-2025")}, ok: true},
+		{name: "remove end date", patch: models.UpdateSubscription{EndDate: ptr("")}, ok: true},
+		{name: "nothing to update", patch: models.UpdateSubscription{}, ok: false},
+		{name: "empty service name", patch: models.UpdateSubscription{ServiceName: ptr("")}, ok: false},
+		{name: "zero price", patch: models.UpdateSubscription{Price: &zero}, ok: false},
+		{name: "bad start date", patch: models.UpdateSubscription{StartDate: ptr("2025-07")}, ok: false},
+		{name: "end date before the stored start", patch: models.UpdateSubscription{EndDate: ptr("06-2025")}, ok: false},
+		{name: "start date after the stored end", patch: models.UpdateSubscription{StartDate: ptr("01-2026")}, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			repo.stored = &models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025", EndDate: "12-2025"}
+			_, err := s.Patch("1", &tt.patch, nil, &models.AuditMeta{})
+			if (err == nil) != tt.ok {
+				t.Fatalf("Patch(%+v) error = %v, want ok %v", tt.patch, err, tt.ok)
+			}
+			if tt.ok && (len(repo.updates) != 1 || *repo.updates[0] != tt.patch) {
+				t.Errorf("Patch(%+v) passed %v to the repository", tt.patch, repo.updates)
+			}
+			if !tt.ok && len(repo.updates) != 0 {
+				t.Errorf("Patch(%+v) updated the repository", tt.patch)
+			}
+		})
+	}
+}
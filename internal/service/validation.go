@@ -0,0 +1,248 @@
+package service
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/pkg/cursor"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// maxBulkItems limits the items of a bulk request.
+	maxBulkItems = 1000
+	// maxIdempotencyKeyLength is the length of the idempotency_keys.key column.
+	maxIdempotencyKeyLength = 255
+	// maxExchangeRate bounds the exchange_rates.rate NUMERIC(20, 10) column.
+	maxExchangeRate = 1e10
+)
+
+var billingPeriods = []string{models.BillingWeekly, models.BillingMonthly, models.BillingQuarterly, models.BillingYearly}
+
+func validateRequired(verr *suberrors.ValidationError, field string, value string) {
+	if value == "" {
+		verr.Add(field, suberrors.CodeRequired, field+" is required")
+	}
+}
+
+func validatePrice(verr *suberrors.ValidationError, price money.Money) {
+	switch {
+	case price.Amount == 0:
+		verr.Add("price.amount", suberrors.CodeRequired, "price.amount is required")
+	case price.Amount < 0:
+		verr.Add("price.amount", suberrors.CodeOutOfRange, "price.amount must be positive")
+	}
+	validateCurrency(verr, "price.currency", price.Currency)
+}
+
+// validateCurrency checks an optional ISO 4217 currency code.
+func validateCurrency(verr *suberrors.ValidationError, field string, currency string) {
+	if currency != "" && !money.IsValidCurrency(currency) {
+		verr.Add(field, suberrors.CodeInvalidFormat, field+" must be an ISO 4217 currency code such as RUB")
+	}
+}
+
+// validateMonthYear checks that a non-empty value is in MM-YYYY format and
+// reports whether it is usable for further checks.
+func validateMonthYear(verr *suberrors.ValidationError, field string, value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsValidMMYYYY(value) {
+		verr.Add(field, suberrors.CodeInvalidFormat, field+" must be in MM-YYYY format")
+		return false
+	}
+	return true
+}
+
+// validatePeriod checks start_date and an optional end_date of a subscription.
+func validatePeriod(verr *suberrors.ValidationError, startDate string, endDate string) {
+	validateRequired(verr, "start_date", startDate)
+	startOk := validateMonthYear(verr, "start_date", startDate)
+	endOk := validateMonthYear(verr, "end_date", endDate)
+	if startOk && endOk && !IsOrderedMMYYYY(startDate, endDate) {
+		verr.Add("end_date", suberrors.CodeInvalidRange, "end_date must not be before start_date")
+	}
+}
+
+func validateId(id string) error {
+	verr := &suberrors.ValidationError{}
+	validateRequired(verr, "id", id)
+	return verr.OrNil()
+}
+
+func valueOf[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func validateFilter(filter *models.SubscriptionFilter) error {
+	verr := &suberrors.ValidationError{}
+	if filter.Limit < 1 || filter.Limit > maxPageSize {
+		verr.Add("limit", suberrors.CodeOutOfRange, fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+	}
+	addFilterErrors(verr, filter)
+	if filter.Cursor != "" {
+		c, err := cursor.Decode(filter.Cursor)
+		if err == nil && uuid.Validate(c.Id) != nil {
+			// the id is compared with the uuid primary key column
+			err = cursor.ErrInvalidCursor
+		}
+		if err == nil && c.Sort == filter.Sort {
+			field, _, _ := repository.ParseSort(filter.Sort)
+			_, err = repository.CursorArg(field, c.Value)
+		}
+		if err != nil || c.Sort != filter.Sort {
+			verr.Add("cursor", suberrors.CodeInvalidFormat, "cursor is malformed or was issued for another sort order")
+		}
+	}
+	return verr.OrNil()
+}
+
+// validateExportFilter checks the filter of an export, which has no pages.
+func validateExportFilter(filter *models.SubscriptionFilter) error {
+	verr := &suberrors.ValidationError{}
+	addFilterErrors(verr, filter)
+	return verr.OrNil()
+}
+
+// addFilterErrors checks the conditions and the sort order of filter.
+func addFilterErrors(verr *suberrors.ValidationError, filter *models.SubscriptionFilter) {
+	if filter.MinPrice != nil && *filter.MinPrice < 0 {
+		verr.Add("min_price", suberrors.CodeOutOfRange, "min_price must not be negative")
+	}
+	if filter.MaxPrice != nil && *filter.MaxPrice < 0 {
+		verr.Add("max_price", suberrors.CodeOutOfRange, "max_price must not be negative")
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		verr.Add("max_price", suberrors.CodeInvalidRange, "max_price must not be less than min_price")
+	}
+	validateCurrency(verr, "currency", filter.Currency)
+	validateOneOf(verr, "billing_period", filter.BillingPeriod, billingPeriods...)
+	validateMonthYear(verr, "active_at", filter.ActiveAt)
+	validateDateRange(verr, "start_from", filter.StartFrom, "start_to", filter.StartTo)
+	validateDateRange(verr, "end_from", filter.EndFrom, "end_to", filter.EndTo)
+	if _, _, ok := repository.ParseSort(filter.Sort); !ok {
+		verr.Add("sort", suberrors.CodeInvalidFormat, "sort must be one of price, start_date, service_name, optionally prefixed with -")
+	}
+}
+
+// validateDateRange checks an optional MM-YYYY range whose ends may be omitted.
+func validateDateRange(verr *suberrors.ValidationError, fromField string, from string, toField string, to string) {
+	fromOk := validateMonthYear(verr, fromField, from)
+	toOk := validateMonthYear(verr, toField, to)
+	if fromOk && toOk && !IsOrderedMMYYYY(from, to) {
+		verr.Add(toField, suberrors.CodeInvalidRange, toField+" must not be before "+fromField)
+	}
+}
+
+// validateReportFilter checks the filter of sums and reports, both ends of
+// the window are required.
+func validateReportFilter(filter *models.ReportFilter) error {
+	verr := &suberrors.ValidationError{}
+	addReportFilterErrors(verr, filter)
+	return verr.OrNil()
+}
+
+func addReportFilterErrors(verr *suberrors.ValidationError, filter *models.ReportFilter) {
+	validateRequired(verr, "end_date", filter.EndDate)
+	validatePeriod(verr, filter.StartDate, filter.EndDate)
+	validateCurrency(verr, "currency", filter.Currency)
+	validateOneOf(verr, "mode", filter.Mode, models.CostCharged, models.CostAmortized)
+}
+
+// validateOneOf checks that an optional value is one of allowed.
+func validateOneOf(verr *suberrors.ValidationError, field string, value string, allowed ...string) {
+	if value != "" && !slices.Contains(allowed, value) {
+		verr.Add(field, suberrors.CodeInvalidFormat, field+" must be one of "+strings.Join(allowed, ", "))
+	}
+}
+
+func validateAggregateFilter(filter *models.AggregateFilter) error {
+	verr := &suberrors.ValidationError{}
+	addReportFilterErrors(verr, &filter.ReportFilter)
+	validateRequired(verr, "group_by", filter.GroupBy)
+	if filter.GroupBy != "" && !repository.IsValidGroupBy(filter.GroupBy) {
+		verr.Add("group_by", suberrors.CodeInvalidFormat, "group_by must be one of service_name, user_id, month")
+	}
+	if _, _, ok := repository.ParseAggregateOrder(filter.OrderBy); !ok {
+		verr.Add("order_by", suberrors.CodeInvalidFormat, "order_by must be one of key, count, sum, avg, min, max, optionally prefixed with -")
+	}
+	if filter.Limit < 0 || filter.Limit > maxPageSize {
+		verr.Add("limit", suberrors.CodeOutOfRange, fmt.Sprintf("limit must be between 0 and %d", maxPageSize))
+	}
+	return verr.OrNil()
+}
+
+func validateExchangeRates(rates []*models.ExchangeRate) error {
+	verr := &suberrors.ValidationError{}
+	if len(rates) == 0 {
+		verr.Add("rates", suberrors.CodeRequired, "at least one exchange rate is required")
+	}
+	for i, rate := range rates {
+		field := fmt.Sprintf("rates[%d]", i)
+		if rate == nil {
+			verr.Add(field, suberrors.CodeRequired, field+" is required")
+			continue
+		}
+		validateRequired(verr, field+".base", rate.Base)
+		validateCurrency(verr, field+".base", rate.Base)
+		validateRequired(verr, field+".quote", rate.Quote)
+		validateCurrency(verr, field+".quote", rate.Quote)
+		if rate.Base != "" && rate.Base == rate.Quote {
+			verr.Add(field+".quote", suberrors.CodeInvalidRange, field+".quote must differ from base")
+		}
+		// NaN fails every comparison, so the range is checked for being inside
+		if !(rate.Rate > 0 && rate.Rate < maxExchangeRate) {
+			verr.Add(field+".rate", suberrors.CodeOutOfRange, fmt.Sprintf("%s.rate must be positive and less than %g", field, maxExchangeRate))
+		}
+		validateRequired(verr, field+".effective_from", rate.EffectiveFrom)
+		validateMonthYear(verr, field+".effective_from", rate.EffectiveFrom)
+	}
+	return verr.OrNil()
+}
+
+// validatePriceChange checks that a price change falls within the billing
+// months of sub after the one it started in, the starting price is changed
+// with an update instead.
+func validatePriceChange(verr *suberrors.ValidationError, change *models.PriceChange, sub *models.Subscription) {
+	if IsOrderedMMYYYY(change.EffectiveFrom, sub.StartDate) {
+		verr.Add("effective_from", suberrors.CodeInvalidRange, "effective_from must be after start_date "+sub.StartDate)
+	}
+	if sub.EndDate != "" && !IsOrderedMMYYYY(change.EffectiveFrom, sub.EndDate) {
+		verr.Add("effective_from", suberrors.CodeInvalidRange, "effective_from must not be after end_date "+sub.EndDate)
+	}
+}
+
+// isPrintableASCII reports whether s consists of visible ASCII characters and spaces only.
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// validateBulk checks the mode and the number of items of a bulk request.
+func validateBulk(mode string, items int) error {
+	verr := &suberrors.ValidationError{}
+	validateRequired(verr, "mode", mode)
+	validateOneOf(verr, "mode", mode, models.BulkAtomic, models.BulkBestEffort)
+	switch {
+	case items == 0:
+		verr.Add("items", suberrors.CodeRequired, "items are required")
+	case items > maxBulkItems:
+		verr.Add("items", suberrors.CodeOutOfRange, fmt.Sprintf("items must contain at most %d items", maxBulkItems))
+	}
+	return verr.OrNil()
+}
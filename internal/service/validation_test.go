@@ -0,0 +1,183 @@
+package service
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/cursor"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCreateValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  *models.Subscription
+		want []suberrors.FieldError
+	}{
+		{
+			name: "empty",
+			sub:  &models.Subscription{},
+			want: []suberrors.FieldError{
+				{Field: "service_name", Code: suberrors.CodeRequired, Message: "service_name is required"},
+				{Field: "price.amount", Code: suberrors.CodeRequired, Message: "price.amount is required"},
+				{Field: "user_id", Code: suberrors.CodeRequired, Message: "user_id is required"},
+				{Field: "start_date", Code: suberrors.CodeRequired, Message: "start_date is required"},
+			},
+		},
+		{
+			name: "bad price and dates",
+			sub:  &models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: -1, Currency: "rub"}, UserId: "user123", StartDate: "2025-07", EndDate: "13-2025"},
+			want: []suberrors.FieldError{
+				{Field: "price.amount", Code: suberrors.CodeOutOfRange, Message: "price.amount must be positive"},
+				{Field: "price.currency", Code: suberrors.CodeInvalidFormat, Message: "price.currency must be an ISO 4217 currency code such as RUB"},
+				{Field: "start_date", Code: suberrors.CodeInvalidFormat, Message: "start_date must be in MM-YYYY format"},
+				{Field: "end_date", Code: suberrors.CodeInvalidFormat, Message: "end_date must be in MM-YYYY format"},
+			},
+		},
+		{
+			name: "unknown billing period",
+			sub:  &models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: 40000, Currency: "RUB"}, BillingPeriod: "daily", UserId: "user123", StartDate: "07-2025"},
+			want: []suberrors.FieldError{
+				{Field: "billing_period", Code: suberrors.CodeInvalidFormat, Message: "billing_period must be one of weekly, monthly, quarterly, yearly"},
+			},
+		},
+		{
+			name: "end before start",
+			sub:  &models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025", EndDate: "06-2025"},
+			want: []suberrors.FieldError{
+				{Field: "end_date", Code: suberrors.CodeInvalidRange, Message: "end_date must not be before start_date"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			_, err := s.Create(tt.sub, &models.AuditMeta{})
+			var verr *suberrors.ValidationError
+			if !errors.As(err, &verr) || !errors.Is(err, suberrors.ErrValidation) {
+				t.Fatalf("Create error = %v, want a ValidationError", err)
+			}
+			if !slices.Equal(verr.Fields, tt.want) {
+				t.Errorf("Create fields = %+v, want %+v", verr.Fields, tt.want)
+			}
+			if len(repo.created) != 0 {
+				t.Errorf("Create stored an invalid subscription")
+			}
+		})
+	}
+}
+
+func TestPatchValidation(t *testing.T) {
+	s, repo := newTestService(t)
+	repo.stored = &models.Subscription{ServiceName: "Netflix", Price: money.Money{Amount: 400, Currency: "RUB"}, UserId: "user123", StartDate: "07-2025"}
+	endDate := "06-2025"
+	_, err := s.Patch("1", &models.UpdateSubscription{EndDate: &endDate}, nil, &models.AuditMeta{})
+	var verr *suberrors.ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "end_date" || verr.Fields[0].Code != suberrors.CodeInvalidRange {
+		t.Errorf("Patch error = %v, want end_date invalid_range", err)
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	const cursorId = "550e8400-e29b-41d4-a716-446655440000"
+	price := func(p int64) *int64 { return &p }
+	priceCursor := cursor.Encode(cursor.Cursor{Sort: "price", Value: "400", Id: cursorId})
+	tests := []struct {
+		name   string
+		filter models.SubscriptionFilter
+		fields []string
+	}{
+		{name: "valid", filter: models.SubscriptionFilter{Limit: 10, Sort: "price", Cursor: priceCursor}},
+		{name: "limit", filter: models.SubscriptionFilter{Limit: maxPageSize + 1}, fields: []string{"limit"}},
+		{name: "price range", filter: models.SubscriptionFilter{Limit: 10, MinPrice: price(500), MaxPrice: price(100)}, fields: []string{"max_price"}},
+		{name: "date range", filter: models.SubscriptionFilter{Limit: 10, StartFrom: "05-2025", StartTo: "01-2025", ActiveAt: "2025"}, fields: []string{"active_at", "start_to"}},
+		{name: "sort", filter: models.SubscriptionFilter{Limit: 10, Sort: "user_id"}, fields: []string{"sort"}},
+		{name: "cursor of another sort", filter: models.SubscriptionFilter{Limit: 10, Sort: "-price", Cursor: priceCursor}, fields: []string{"cursor"}},
+		{name: "malformed cursor", filter: models.SubscriptionFilter{Limit: 10, Cursor: "!!!"}, fields: []string{"cursor"}},
+		{name: "cursor id is not a uuid", filter: models.SubscriptionFilter{Limit: 10, Sort: "price", Cursor: cursor.Encode(cursor.Cursor{Sort: "price", Value: "400", Id: "1"})}, fields: []string{"cursor"}},
+		{name: "cursor value of another field", filter: models.SubscriptionFilter{Limit: 10, Sort: "price", Cursor: cursor.Encode(cursor.Cursor{Sort: "price", Value: "Netflix", Id: cursorId})}, fields: []string{"cursor"}},
+		{name: "cursor date", filter: models.SubscriptionFilter{Limit: 10, Sort: "start_date", Cursor: cursor.Encode(cursor.Cursor{Sort: "start_date", Value: "13-2025", Id: cursorId})}, fields: []string{"cursor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFilter(&tt.filter)
+			var fields []string
+			var verr *suberrors.ValidationError
+			if errors.As(err, &verr) {
+				for _, field := range verr.Fields {
+					fields = append(fields, field.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("validateFilter error = %v", err)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("validateFilter fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestValidateExchangeRates(t *testing.T) {
+	tests := []struct {
+		name   string
+		rates  []*models.ExchangeRate
+		fields []string
+	}{
+		{name: "valid", rates: []*models.ExchangeRate{{Base: "USD", Quote: "RUB", Rate: 92.5, EffectiveFrom: "01-2025"}}},
+		{name: "empty", rates: nil, fields: []string{"rates"}},
+		{name: "same currencies", rates: []*models.ExchangeRate{{Base: "RUB", Quote: "RUB", Rate: 1, EffectiveFrom: "01-2025"}}, fields: []string{"rates[0].quote"}},
+		{
+			name: "bad fields",
+			rates: []*models.ExchangeRate{
+				{Base: "USD", Quote: "RUB", Rate: 92.5, EffectiveFrom: "01-2025"},
+				{Base: "usd", Quote: "", Rate: 0, EffectiveFrom: "2025-01"},
+			},
+			fields: []string{"rates[1].base", "rates[1].quote", "rates[1].rate", "rates[1].effective_from"},
+		},
+		{name: "negative rate", rates: []*models.ExchangeRate{{Base: "USD", Quote: "RUB", Rate: -1, EffectiveFrom: "01-2025"}}, fields: []string{"rates[0].rate"}},
+		{name: "NaN rate", rates: []*models.ExchangeRate{{Base: "USD", Quote: "RUB", Rate: math.NaN(), EffectiveFrom: "01-2025"}}, fields: []string{"rates[0].rate"}},
+		{name: "infinite rate", rates: []*models.ExchangeRate{{Base: "USD", Quote: "RUB", Rate: math.Inf(1), EffectiveFrom: "01-2025"}}, fields: []string{"rates[0].rate"}},
+		{name: "rate too large", rates: []*models.ExchangeRate{{Base: "USD", Quote: "RUB", Rate: 1e10, EffectiveFrom: "01-2025"}}, fields: []string{"rates[0].rate"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateExchangeRates(tt.rates)
+			var fields []string
+			var verr *suberrors.ValidationError
+			if errors.As(err, &verr) {
+				for _, field := range verr.Fields {
+					fields = append(fields, field.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("validateExchangeRates error = %v", err)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("validateExchangeRates fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestValidatePriceChange(t *testing.T) {
+	sub := &models.Subscription{StartDate: "03-2025", EndDate: "12-2025"}
+	tests := []struct {
+		effectiveFrom string
+		ok            bool
+	}{
+		{effectiveFrom: "04-2025", ok: true},
+		{effectiveFrom: "12-2025", ok: true},
+		{effectiveFrom: "03-2025", ok: false},
+		{effectiveFrom: "01-2025", ok: false},
+		{effectiveFrom: "01-2026", ok: false},
+	}
+	for _, tt := range tests {
+		verr := &suberrors.ValidationError{}
+		validatePriceChange(verr, &models.PriceChange{EffectiveFrom: tt.effectiveFrom}, sub)
+		if (verr.OrNil() == nil) != tt.ok {
+			t.Errorf("validatePriceChange(%s) = %v, want ok %v", tt.effectiveFrom, verr.OrNil(), tt.ok)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHistoryRecordsActor(t *testing.T) {
+	handler := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/subscriptions",
+		strings.NewReader(`{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"user123","start_date":"01-2025"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Actor", "billing-service")
+	req.Header.Set("X-Request-ID", "req-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	id := strings.TrimPrefix(rec.Header().Get("Location"), "/api/v2/subscriptions/")
+
+	rec = serve(t, handler, http.MethodDelete, "/api/v2/subscriptions/"+id, "", nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	var history models.HistoryResponse
+	rec = serve(t, handler, http.MethodGet, "/api/v2/subscriptions/"+id+"/history", "", &history)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("history: status %d, body %s", rec.Code, rec.Body)
+	}
+	if len(history.Entries) != 2 {
+		t.Fatalf("history = %+v, want 2 entries", history.Entries)
+	}
+	create, remove := history.Entries[0], history.Entries[1]
+	if create.Action != models.AuditCreate || create.Actor != "billing-service" || create.RequestId != "req-1" {
+		t.Errorf("create entry = %+v", create)
+	}
+	// requests without X-Actor are recorded as anonymous
+	if remove.Action != models.AuditDelete || remove.Actor != anonymousActor || remove.RequestId == "" {
+		t.Errorf("delete entry = %+v", remove)
+	}
+}
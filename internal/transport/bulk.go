@@ -0,0 +1,160 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// @Summary Создаёт пакет подписок
+// @Description В режиме atomic подписки создаются все или ни одной, в режиме best_effort создаются все, для которых это возможно.
+// @Description Каждый элемент проверяется как при создании одной подписки, в results - статус, который получил бы отдельный запрос.
+// @Tags Пакетные операции
+// @Accept json
+// @Produce json
+// @Param input body models.BulkCreateRequest true "Режим и подписки для создания (не более 1000)"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param Idempotency-Key header string false "Ключ идемпотентности: повтор запроса с тем же ключом и телом возвращает исходный ответ" example(3f1c2a9e-7b4d-4e0a-9c55-1d2e3f4a5b6c)
+// @Success 201 {object} models.BulkResponse "Все подписки созданы"
+// @Success 207 {object} models.BulkResponse "В режиме best_effort часть подписок не создана, ошибка каждой - в results"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Запрос с тем же ключом идемпотентности ещё выполняется"
+// @Failure 413 {object} models.BadResponse "Тело запроса с ключом идемпотентности больше 4 МиБ"
+// @Failure 422 {object} models.BulkResponse "В режиме atomic подписка не создана и пакет отменён, ошибка каждой - в results"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/bulk/create [post]
+func BulkCreateSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode, request, err := bindBulkJSON[models.CreateSubscription](c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		subs := make([]*models.Subscription, len(request))
+		for i := range request {
+			subs[i] = request[i].Subscription()
+		}
+		errs, err := s.Service.BulkCreate(subs, mode, auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		results := make([]models.BulkItemResult, len(subs))
+		for i, sub := range subs {
+			if errs[i] == nil {
+				results[i].Id, results[i].Subscription = sub.Id, sub
+			}
+		}
+		writeBulkResponse(c, mode, results, errs, http.StatusCreated)
+	}
+}
+
+// @Summary Изменяет пакет подписок
+// @Description Каждый элемент частично изменяет подписку id как PATCH, version работает как заголовок If-Match.
+// @Description В режиме atomic изменения применяются все или ни одного, в режиме best_effort применяются все, для которых это возможно.
+// @Tags Пакетные операции
+// @Accept json
+// @Produce json
+// @Param input body models.BulkUpdateRequest true "Режим и изменения подписок (не более 1000)"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Success 200 {object} models.BulkResponse "Все подписки изменены"
+// @Success 207 {object} models.BulkResponse "В режиме best_effort часть подписок не изменена, ошибка каждой - в results"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.BulkResponse "В режиме atomic подписка не изменена и пакет отменён, ошибка каждой - в results"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/bulk/update [post]
+func BulkUpdateSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode, request, err := bindBulkJSON[models.BulkUpdateItem](c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		items := make([]*models.BulkUpdateItem, len(request))
+		for i := range request {
+			items[i] = &request[i]
+		}
+		updated, errs, err := s.Service.BulkUpdate(items, mode, auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		results := make([]models.BulkItemResult, len(items))
+		for i, item := range items {
+			results[i].Id, results[i].Subscription = item.Id, updated[i]
+		}
+		writeBulkResponse(c, mode, results, errs, http.StatusOK)
+	}
+}
+
+// @Summary Удаляет пакет подписок
+// @Description Каждый элемент удаляет подписку id как DELETE, version работает как заголовок If-Match.
+// @Description В режиме atomic удаляются все подписки или ни одной, в режиме best_effort удаляются все, для которых это возможно.
+// @Tags Пакетные операции
+// @Accept json
+// @Produce json
+// @Param input body models.BulkDeleteRequest true "Режим и подписки для удаления (не более 1000)"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Success 200 {object} models.BulkResponse "Все подписки удалены"
+// @Success 207 {object} models.BulkResponse "В режиме best_effort часть подписок не удалена, ошибка каждой - в results"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.BulkResponse "В режиме atomic подписка не удалена и пакет отменён, ошибка каждой - в results"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/bulk/delete [post]
+func BulkDeleteSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode, request, err := bindBulkJSON[models.BulkDeleteItem](c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		items := make([]*models.BulkDeleteItem, len(request))
+		for i := range request {
+			items[i] = &request[i]
+		}
+		errs, err := s.Service.BulkDelete(items, mode, auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		results := make([]models.BulkItemResult, len(items))
+		for i, item := range items {
+			results[i].Id = item.Id
+		}
+		writeBulkResponse(c, mode, results, errs, http.StatusNoContent)
+	}
+}
+
+// writeBulkResponse completes results with the status and problem of every
+// item, okStatus is the status of an item that succeeded. The response is 207
+// when a best effort batch partly failed and 422 when an atomic batch was
+// rolled back.
+func writeBulkResponse(c *gin.Context, mode string, results []models.BulkItemResult, errs []error, okStatus int) {
+	response := models.BulkResponse{Mode: mode, Results: results}
+	for i, err := range errs {
+		result := &results[i]
+		result.Index = i
+		if err == nil {
+			result.Status = okStatus
+			response.Succeeded++
+			continue
+		}
+		problem := errorProblem(c, err)
+		problem.Instance, problem.RequestId = "", ""
+		result.Status, result.Subscription, result.Error = problem.Status, nil, &problem
+		response.Failed++
+	}
+	status := http.StatusOK
+	switch {
+	case response.Failed == 0 && okStatus == http.StatusCreated:
+		status = http.StatusCreated
+	case response.Failed > 0 && mode == models.BulkAtomic:
+		status = http.StatusUnprocessableEntity
+	case response.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, response)
+}
@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"net/http"
+	"testing"
+)
+
+const bulkItems = `[
+	{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"user123","start_date":"01-2025"},
+	{"service_name":"Spotify","price":{"amount":-1,"currency":"USD"},"user_id":"user123","start_date":"01-2025"},
+	{"service_name":"Apple","price":{"amount":500,"currency":"USD"},"user_id":"user123","start_date":"01-2025"}
+]`
+
+func TestBulkCreate(t *testing.T) {
+	tests := []struct {
+		mode     string
+		status   int
+		statuses []int
+		created  int
+	}{
+		{mode: models.BulkAtomic, status: http.StatusUnprocessableEntity, statuses: []int{http.StatusFailedDependency, http.StatusBadRequest, http.StatusFailedDependency}},
+		{mode: models.BulkBestEffort, status: http.StatusMultiStatus, statuses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusCreated}, created: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			handler := newTestServer(t)
+			var response models.BulkResponse
+			rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions/bulk/create",
+				`{"mode":"`+tt.mode+`","items":`+bulkItems+`}`, &response)
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d, body %s", rec.Code, tt.status, rec.Body)
+			}
+			if response.Succeeded != tt.created || response.Failed != len(tt.statuses)-tt.created {
+				t.Errorf("succeeded %d, failed %d", response.Succeeded, response.Failed)
+			}
+			for i, result := range response.Results {
+				if result.Index != i || result.Status != tt.statuses[i] {
+					t.Errorf("results[%d] = index %d, status %d, want status %d", i, result.Index, result.Status, tt.statuses[i])
+				}
+				if (result.Status == http.StatusCreated) != (result.Subscription != nil) {
+					t.Errorf("results[%d]: status %d with subscription %+v", i, result.Status, result.Subscription)
+				}
+			}
+
+			var list models.ListSubscriptionsResponse
+			if rec := serve(t, handler, http.MethodGet, "/api/v2/subscriptions?user_id=user123", "", &list); rec.Code != http.StatusOK {
+				t.Fatalf("list: status %d, body %s", rec.Code, rec.Body)
+			}
+			if len(list.Subscriptions) != tt.created {
+				t.Errorf("list: %d subscriptions, want %d", len(list.Subscriptions), tt.created)
+			}
+		})
+	}
+}
+
+func TestBulkInvalidMode(t *testing.T) {
+	handler := newTestServer(t)
+	rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions/bulk/delete", `{"mode":"some","items":[{"id":"1"}]}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestBulkDecodeErrorFields(t *testing.T) {
+	tests := []struct {
+		target string
+		body   string
+		field  string
+		code   string
+	}{
+		{
+			target: "/api/v2/subscriptions/bulk/create",
+			body:   `{"mode":"atomic","items":[{"service_name":"Netflix"},{"service_name":"Spotify","price":"400"}]}`,
+			field:  "items[1].price",
+			code:   suberrors.CodeInvalidType,
+		},
+		{
+			target: "/api/v2/subscriptions/bulk/update",
+			body:   `{"mode":"atomic","items":[{"id":"1","price":92233720368547759}]}`,
+			field:  "items[0].price",
+			code:   suberrors.CodeOutOfRange,
+		},
+		{
+			target: "/api/v2/subscriptions/bulk/delete",
+			body:   `{"mode":"atomic","items":[{"id":"1"},{"id":"2","version":"1"}]}`,
+			field:  "items[1].version",
+			code:   suberrors.CodeInvalidType,
+		},
+		{
+			target: "/api/v2/subscriptions/bulk/delete",
+			body:   `{"mode":"atomic","items":["1"]}`,
+			field:  "items[0]",
+			code:   suberrors.CodeInvalidType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			handler := newTestServer(t)
+			var problem models.BadResponse
+			rec := serve(t, handler, http.MethodPost, tt.target, tt.body, &problem)
+			if rec.Code != http.StatusBadRequest || len(problem.Errors) != 1 {
+				t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+			}
+			if got := problem.Errors[0]; got.Field != tt.field || got.Code != tt.code {
+				t.Errorf("error field %s/%s, want %s/%s", got.Field, got.Code, tt.field, tt.code)
+			}
+		})
+	}
+}
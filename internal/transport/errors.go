@@ -0,0 +1,218 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+const problemContentType = "application/problem+json"
+
+// problemTypes maps sentinel errors to the problem they are rendered as.
+// The first match wins, so more specific errors go first.
+var problemTypes = []struct {
+	err    error
+	status int
+	slug   string
+	title  string
+}{
+	{suberrors.ErrValidation, http.StatusBadRequest, "validation-error", "Validation failed"},
+	{suberrors.ErrIdSubscriptionNotFound, http.StatusNotFound, "subscription-not-found", "Subscription id not found"},
+	{suberrors.ErrUserIdNotFound, http.StatusNotFound, "user-not-found", "User id not found"},
+	{suberrors.ErrSubscriptionConflict, http.StatusConflict, "subscription-conflict", "Subscription conflicts with an existing one"},
+	{suberrors.ErrConstraintViolation, http.StatusUnprocessableEntity, "constraint-violation", "Subscription violates a constraint"},
+	{suberrors.ErrMixedCurrencies, http.StatusUnprocessableEntity, "mixed-currencies", "Subscriptions are billed in different currencies"},
+	{suberrors.ErrExchangeRateNotFound, http.StatusUnprocessableEntity, "exchange-rate-not-found", "Exchange rate not found"},
+	{suberrors.ErrVersionConflict, http.StatusPreconditionFailed, "version-conflict", "Subscription was modified by another request"},
+	{suberrors.ErrPreconditionRequired, http.StatusPreconditionRequired, "precondition-required", "If-Match header is required"},
+	{suberrors.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency-key-reused", "Idempotency key was used with a different request"},
+	{suberrors.ErrIdempotencyInProgress, http.StatusConflict, "idempotency-in-progress", "Request with this idempotency key is in progress"},
+	{suberrors.ErrBulkAborted, http.StatusFailedDependency, "bulk-aborted", "Item of the atomic batch was not applied"},
+	{suberrors.ErrSubscriptionNotDeleted, http.StatusConflict, "subscription-not-deleted", "Subscription is not deleted"},
+}
+
+// writeError is the single place where handler errors are turned into
+// application/problem+json responses.
+func writeError(c *gin.Context, err error) {
+	problem := errorProblem(c, err)
+	writeProblemDocument(c, &problem)
+}
+
+// errorProblem renders err as a problem document. The full error chain is
+// only logged, clients get the message of the matched error itself.
+func errorProblem(c *gin.Context, err error) models.BadResponse {
+	_ = c.Error(err)
+	for _, problemType := range problemTypes {
+		if !errors.Is(err, problemType.err) {
+			continue
+		}
+		var fields []suberrors.FieldError
+		var verr *suberrors.ValidationError
+		if errors.As(err, &verr) {
+			fields = verr.Fields
+		}
+		return newProblem(c, problemType.status, problemType.slug, problemType.title, problemDetail(err, problemType.err), fields)
+	}
+	return newProblem(c, http.StatusInternalServerError, "internal-error", "Internal server error", "", nil)
+}
+
+// problemDetail finds the message of sentinel in the chain of err: the
+// sentinel with the context added by the code that detected it, without the
+// wrapping of the layers it went through. Constraint names stay internal.
+func problemDetail(err error, sentinel error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch e := e.(type) {
+		case *suberrors.ValidationError:
+			return e.Error()
+		case *suberrors.ConstraintError:
+			return e.Err.Error()
+		}
+		if e == sentinel || errors.Unwrap(e) == sentinel {
+			return e.Error()
+		}
+	}
+	return sentinel.Error()
+}
+
+func writeProblem(c *gin.Context, status int, slug string, title string, detail string, fields []suberrors.FieldError) {
+	problem := newProblem(c, status, slug, title, detail, fields)
+	writeProblemDocument(c, &problem)
+}
+
+func newProblem(c *gin.Context, status int, slug string, title string, detail string, fields []suberrors.FieldError) models.BadResponse {
+	return models.BadResponse{
+		Type:      "/problems/" + slug,
+		Title:     title,
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestId: c.GetString(requestIdKey),
+		Errors:    fields,
+	}
+}
+
+func writeProblemDocument(c *gin.Context, problem *models.BadResponse) {
+	body, err := json.Marshal(problem)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Abort()
+	c.Data(problem.Status, problemContentType, body)
+}
+
+// bindJSON decodes the request body into dst and reports malformed input as
+// a *suberrors.ValidationError.
+func bindJSON(c *gin.Context, dst any) error {
+	if err := c.ShouldBindJSON(dst); err != nil {
+		return decodeError(err, "")
+	}
+	return nil
+}
+
+// bindBulkJSON decodes a bulk request body into its mode and items. Items are
+// decoded one by one, so that a malformed item is reported with its index.
+func bindBulkJSON[T any](c *gin.Context) (string, []T, error) {
+	var request struct {
+		Mode  string            `json:"mode"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := bindJSON(c, &request); err != nil {
+		return "", nil, err
+	}
+	items := make([]T, len(request.Items))
+	for i, item := range request.Items {
+		if err := json.Unmarshal(item, &items[i]); err != nil {
+			return "", nil, decodeError(err, fmt.Sprintf("items[%d]", i))
+		}
+	}
+	return request.Mode, items, nil
+}
+
+// decodeError turns an error decoding a JSON body into a
+// *suberrors.ValidationError, the fields it names are nested in prefix unless
+// it is empty.
+func decodeError(err error, prefix string) error {
+	field := func(name string) string {
+		switch {
+		case prefix == "":
+			return name
+		case name == "":
+			return prefix
+		}
+		return prefix + "." + name
+	}
+	verr := &suberrors.ValidationError{}
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, money.ErrInvalidMoney):
+		// every money field of the request bodies is a price
+		verr.Add(field("price"), suberrors.CodeInvalidType, err.Error())
+	case errors.Is(err, money.ErrAmountOutOfRange):
+		verr.Add(field("price"), suberrors.CodeOutOfRange, err.Error())
+	case errors.As(err, &typeErr):
+		name := field(typeErr.Field)
+		if name == "" {
+			name = "body"
+		}
+		verr.Add(name, suberrors.CodeInvalidType, fmt.Sprintf("%s must be of type %s", name, typeErr.Type))
+	case errors.Is(err, io.EOF):
+		verr.Add("body", suberrors.CodeRequired, "request body is required")
+	default:
+		verr.Add("body", suberrors.CodeInvalidFormat, "request body is not valid JSON")
+	}
+	return verr
+}
+
+// bindQuery decodes query parameters into dst and reports malformed values
+// as a *suberrors.ValidationError naming the parameter.
+func bindQuery(c *gin.Context, dst any) error {
+	verr := &suberrors.ValidationError{}
+	addQueryTypeErrors(verr, c, dst)
+	if err := verr.OrNil(); err != nil {
+		return err
+	}
+	if err := c.ShouldBindQuery(dst); err != nil {
+		verr.Add("query", suberrors.CodeInvalidType, "query parameters are malformed")
+		return verr
+	}
+	return nil
+}
+
+// addQueryTypeErrors checks that the parameters bound to numeric and boolean
+// fields of dst parse, gin's binding errors do not say which one failed.
+func addQueryTypeErrors(verr *suberrors.ValidationError, c *gin.Context, dst any) {
+	t := reflect.TypeOf(dst).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("form")
+		value, ok := c.GetQuery(name)
+		if name == "" || !ok || value == "" {
+			continue
+		}
+		kind := t.Field(i).Type.Kind()
+		if kind == reflect.Pointer {
+			kind = t.Field(i).Type.Elem().Kind()
+		}
+		switch kind {
+		case reflect.Int, reflect.Int64:
+			_, err := strconv.ParseInt(value, 10, 64)
+			if errors.Is(err, strconv.ErrRange) {
+				verr.Add(name, suberrors.CodeOutOfRange, name+" is out of range")
+			} else if err != nil {
+				verr.Add(name, suberrors.CodeInvalidType, name+" must be an integer")
+			}
+		case reflect.Bool:
+			if _, err := strconv.ParseBool(value); err != nil {
+				verr.Add(name, suberrors.CodeInvalidType, name+" must be true or false")
+			}
+		}
+	}
+}
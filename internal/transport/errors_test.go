@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestProblemDetail(t *testing.T) {
+	verr := &suberrors.ValidationError{}
+	verr.Add("start_date", suberrors.CodeInvalidFormat, "start_date must be MM-YYYY")
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		want     string
+	}{
+		{
+			name:     "sentinel",
+			err:      suberrors.ErrIdSubscriptionNotFound,
+			sentinel: suberrors.ErrIdSubscriptionNotFound,
+			want:     suberrors.ErrIdSubscriptionNotFound.Error(),
+		},
+		{
+			name:     "context of the detecting code",
+			err:      fmt.Errorf("error updating subscription: %w", fmt.Errorf("%w: current version is 2", suberrors.ErrVersionConflict)),
+			sentinel: suberrors.ErrVersionConflict,
+			want:     suberrors.ErrVersionConflict.Error() + ": current version is 2",
+		},
+		{
+			name:     "constraint name",
+			err:      fmt.Errorf("error creating subscription: %w", &suberrors.ConstraintError{Constraint: "subscriptions_price_check", Err: suberrors.ErrConstraintViolation}),
+			sentinel: suberrors.ErrConstraintViolation,
+			want:     suberrors.ErrConstraintViolation.Error(),
+		},
+		{
+			name:     "validation",
+			err:      fmt.Errorf("error creating subscription: %w", verr),
+			sentinel: suberrors.ErrValidation,
+			want:     verr.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := problemDetail(tt.err, tt.sentinel); got != tt.want {
+				t.Errorf("problemDetail(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindQueryFields(t *testing.T) {
+	handler := newTestServer(t)
+	var problem models.BadResponse
+	rec := serve(t, handler, http.MethodGet, "/api/v2/subscriptions?limit=ten&min_price=99999999999999999999&user_id=user123", "", &problem)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var fields []string
+	for _, field := range problem.Errors {
+		fields = append(fields, field.Field+"/"+field.Code)
+	}
+	if want := []string{"min_price/" + suberrors.CodeOutOfRange, "limit/" + suberrors.CodeInvalidType}; !slices.Equal(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+}
@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// exchangeRatesColumns are the columns of an exchange rates CSV upload.
+var exchangeRatesColumns = []string{"base", "quote", "rate", "effective_from"}
+
+// @Summary Загружает курсы валют
+// @Description Принимает JSON-массив курсов или CSV с заголовком base,quote,rate,effective_from.
+// @Description Курс действует с месяца effective_from до следующего курса той же пары, существующий курс перезаписывается.
+// @Tags Администрирование
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Security AdminToken
+// @Param input body []models.ExchangeRate true "Курсы валют"
+// @Success 200 {object} models.ExchangeRatesResponse "Сохранённые курсы"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 401 {object} models.BadResponse "Нет или неверный токен администратора"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Failure 503 {object} models.BadResponse "Токен администратора не настроен, маршруты администрирования отключены"
+// @Router /v2/admin/exchange-rates [post]
+func UploadExchangeRatesHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rates, err := bindExchangeRates(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		if err := s.Service.SaveExchangeRates(rates); err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, models.ExchangeRatesResponse{Rates: rates})
+	}
+}
+
+// @Summary Возвращает все курсы валют
+// @Tags Администрирование
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} models.ExchangeRatesResponse "Курсы валют"
+// @Failure 401 {object} models.BadResponse "Нет или неверный токен администратора"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Failure 503 {object} models.BadResponse "Токен администратора не настроен, маршруты администрирования отключены"
+// @Router /v2/admin/exchange-rates [get]
+func ListExchangeRatesHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rates, err := s.Service.ListExchangeRates()
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, models.ExchangeRatesResponse{Rates: rates})
+	}
+}
+
+// bindExchangeRates decodes exchange rates sent as JSON or, for text/csv, as
+// CSV rows.
+func bindExchangeRates(c *gin.Context) ([]*models.ExchangeRate, error) {
+	if c.ContentType() != "text/csv" {
+		var rates []*models.ExchangeRate
+		if err := bindJSON(c, &rates); err != nil {
+			return nil, err
+		}
+		return rates, nil
+	}
+	return readExchangeRatesCSV(c.Request.Body)
+}
+
+// readExchangeRatesCSV reads rates from CSV with a header row naming
+// exchangeRatesColumns in any order.
+func readExchangeRatesCSV(r io.Reader) ([]*models.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	verr := &suberrors.ValidationError{}
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		verr.Add("body", suberrors.CodeRequired, "request body is required")
+		return nil, verr
+	}
+	if err != nil {
+		verr.Add("body", suberrors.CodeInvalidFormat, "request body is not valid CSV")
+		return nil, verr
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range exchangeRatesColumns {
+		if _, ok := columns[name]; !ok {
+			verr.Add("header", suberrors.CodeRequired, "header must contain "+strings.Join(exchangeRatesColumns, ","))
+			return nil, verr
+		}
+	}
+
+	var rates []*models.ExchangeRate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			verr.Add("body", suberrors.CodeInvalidFormat, err.Error())
+			return nil, verr
+		}
+		line, _ := reader.FieldPos(0)
+		field := fmt.Sprintf("line %d", line)
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[columns["rate"]]), 64)
+		if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) {
+			verr.Add(field, suberrors.CodeInvalidType, field+": rate must be a number")
+			continue
+		}
+		rates = append(rates, &models.ExchangeRate{
+			Base:          strings.TrimSpace(record[columns["base"]]),
+			Quote:         strings.TrimSpace(record[columns["quote"]]),
+			Rate:          rate,
+			EffectiveFrom: strings.TrimSpace(record[columns["effective_from"]]),
+		})
+	}
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadExchangeRatesCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   []*models.ExchangeRate
+		fields []string
+	}{
+		{
+			name:  "columns in any order",
+			input: "effective_from, rate, quote, base\n01-2025, 92.5, RUB, USD\n03-2025,100,RUB,USD\n",
+			want: []*models.ExchangeRate{
+				{Base: "USD", Quote: "RUB", Rate: 92.5, EffectiveFrom: "01-2025"},
+				{Base: "USD", Quote: "RUB", Rate: 100, EffectiveFrom: "03-2025"},
+			},
+		},
+		{name: "empty", input: "", fields: []string{"body"}},
+		{name: "missing column", input: "base,quote,rate\nUSD,RUB,92.5\n", fields: []string{"header"}},
+		{name: "rate is not a number", input: "base,quote,rate,effective_from\nUSD,RUB,abc,01-2025\nEUR,RUB,x,01-2025\n", fields: []string{"line 2", "line 3"}},
+		{name: "rate is not finite", input: "base,quote,rate,effective_from\nUSD,RUB,NaN,01-2025\nEUR,RUB,+Inf,01-2025\n", fields: []string{"line 2", "line 3"}},
+		{name: "wrong number of fields", input: "base,quote,rate,effective_from\nUSD,RUB\n", fields: []string{"body"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readExchangeRatesCSV(strings.NewReader(tt.input))
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("readExchangeRatesCSV error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("readExchangeRatesCSV = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			var verr *suberrors.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("readExchangeRatesCSV error = %v, want a ValidationError", err)
+			}
+			var fields []string
+			for _, field := range verr.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("readExchangeRatesCSV fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestUploadExchangeRates(t *testing.T) {
+	handler := newTestServer(t)
+	upload := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/admin/exchange-rates", strings.NewReader("base,quote,rate,effective_from\nUSD,RUB,90,01-2025\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := upload(""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("upload without token: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := upload("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("upload with a wrong token: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := upload(testAdminToken); rec.Code != http.StatusOK {
+		t.Fatalf("upload: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions",
+		`{"service_name":"Netflix","price":{"amount":999,"currency":"USD"},"user_id":"user123","start_date":"01-2025"}`, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	var sum models.SumSubscriptionsResponse
+	rec = serve(t, handler, http.MethodGet, "/api/v2/reports/sum?start_date=01-2025&end_date=02-2025&currency=RUB", "", &sum)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("sum: status %d, body %s", rec.Code, rec.Body)
+	}
+	if want := (models.SumSubscriptionsResponse{Sum: 2 * 999 * 90, Currency: "RUB", Months: 2}); sum != want {
+		t.Errorf("sum = %+v, want %+v", sum, want)
+	}
+}
+
+func TestAdminWithoutToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		open   bool
+		status int
+	}{
+		{name: "disabled", status: http.StatusServiceUnavailable},
+		{name: "open", open: true, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestServerWith(t, &config.Config{AdminOpen: tt.open})
+			if rec := serve(t, handler, http.MethodGet, "/api/v2/admin/exchange-rates", "", nil); rec.Code != tt.status {
+				t.Errorf("status %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
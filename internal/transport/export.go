@@ -0,0 +1,173 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/money"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Formats of an export, negotiated by the Accept header, CSV unless asked
+// for another one.
+const (
+	exportCSV    = "text/csv"
+	exportNDJSON = "application/x-ndjson"
+)
+
+// exportExtensions are the file name extensions of the export formats.
+var exportExtensions = map[string]string{exportCSV: "csv", exportNDJSON: "ndjson"}
+
+// exportColumns are the columns of a CSV export, the import reads the same
+// columns and ignores id and version.
+var exportColumns = []string{"id", "service_name", "price", "currency", "billing_period", "user_id", "start_date", "end_date", "version"}
+
+// exportFlushRows is the number of rows after which the export is flushed to
+// the client.
+const exportFlushRows = 100
+
+// @Summary Выгружает подписки в CSV или NDJSON
+// @Description Подписки, подходящие под фильтры, передаются потоком без постраничной выдачи. Формат выбирается заголовком Accept:
+// @Description text/csv (по умолчанию, цена в основных единицах валюты, как при импорте) или application/x-ndjson (подписка в формате JSON на каждой строке).
+// @Tags Подписки
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param min_price query int false "Минимальная цена в минимальных единицах валюты"
+// @Param max_price query int false "Максимальная цена в минимальных единицах валюты"
+// @Param currency query string false "Валюта (ISO 4217)" example(RUB)
+// @Param billing_period query string false "Период оплаты" Enums(weekly, monthly, quarterly, yearly)
+// @Param active_at query string false "Подписка активна в месяце" example(06-2025)
+// @Param start_from query string false "Начало подписки не раньше" example(01-2025)
+// @Param start_to query string false "Начало подписки не позже" example(12-2025)
+// @Param end_from query string false "Окончание подписки не раньше" example(01-2025)
+// @Param end_to query string false "Окончание подписки не позже" example(12-2025)
+// @Param sort query string false "Поле сортировки, - для убывания" Enums(price, -price, start_date, -start_date, service_name, -service_name)
+// @Success 200 {string} string "Подписки в выбранном формате"
+// @Header 200 {string} Content-Disposition "Имя файла выгрузки, например attachment; filename=\"subscriptions-20250101.csv\""
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 406 {object} models.BadResponse "Запрошенный в Accept формат не поддерживается"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/export [get]
+func ExportSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.NegotiateFormat(exportCSV, exportNDJSON)
+		if format == "" {
+			writeProblem(c, http.StatusNotAcceptable, "not-acceptable", "Not acceptable",
+				fmt.Sprintf("export is available as %s or %s", exportCSV, exportNDJSON), nil)
+			return
+		}
+		var filter models.SubscriptionFilter
+		if err := bindQuery(c, &filter); err != nil {
+			writeError(c, err)
+			return
+		}
+
+		export := newExportWriter(c, format)
+		err := s.Service.ExportSubscriptions(&filter, export.write)
+		if err == nil {
+			err = export.close()
+		}
+		if err == nil {
+			return
+		}
+		if !export.started {
+			writeError(c, err)
+			return
+		}
+		// the status is already sent, the client gets a cut off file
+		logger.GetLoggerFromCtx(s.ctx).Error("export failed",
+			zap.String("request_id", c.GetString(requestIdKey)), zap.Error(err))
+	}
+}
+
+// exportWriter streams subscriptions to the response. The response starts
+// with the first subscription, so that an export failing before it still
+// gets a problem response.
+type exportWriter struct {
+	c       *gin.Context
+	format  string
+	started bool
+	rows    int
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+func newExportWriter(c *gin.Context, format string) *exportWriter {
+	return &exportWriter{c: c, format: format}
+}
+
+func (w *exportWriter) start() error {
+	w.started = true
+	filename := fmt.Sprintf("subscriptions-%s.%s", time.Now().UTC().Format("20060102"), exportExtensions[w.format])
+	w.c.Header("Content-Type", w.format+"; charset=utf-8")
+	w.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.c.Status(http.StatusOK)
+	if w.format == exportNDJSON {
+		w.json = json.NewEncoder(w.c.Writer)
+		return nil
+	}
+	w.csv = csv.NewWriter(w.c.Writer)
+	return w.csv.Write(exportColumns)
+}
+
+func (w *exportWriter) write(sub *models.Subscription) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	var err error
+	if w.json != nil {
+		err = w.json.Encode(sub)
+	} else {
+		err = w.csv.Write([]string{
+			sub.Id,
+			sub.ServiceName,
+			money.FormatAmount(sub.Price.Amount),
+			sub.Price.Currency,
+			sub.BillingPeriod,
+			sub.UserId,
+			sub.StartDate,
+			sub.EndDate,
+			strconv.FormatInt(sub.Version, 10),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if w.rows++; w.rows%exportFlushRows == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+// close finishes the export, an export without subscriptions is an empty
+// file, with only the header row for CSV.
+func (w *exportWriter) close() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
+
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	w.c.Writer.Flush()
+	return nil
+}
@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func exportRequest(handler http.Handler, target string, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestExportSubscriptions(t *testing.T) {
+	handler := newTestServer(t)
+	for _, body := range []string{
+		`{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"user123","start_date":"01-2025"}`,
+		`{"service_name":"Spotify","price":{"amount":29900,"currency":"RUB"},"user_id":"user123","start_date":"02-2025","end_date":"12-2025"}`,
+		`{"service_name":"Apple","price":{"amount":500,"currency":"RUB"},"user_id":"user456","start_date":"01-2025"}`,
+	} {
+		if rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions", body, nil); rec.Code != http.StatusCreated {
+			t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec := exportRequest(handler, "/api/v2/subscriptions/export?user_id=user123&sort=-price", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("csv: status %d, body %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("csv: Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="subscriptions-`) || !strings.HasSuffix(got, `.csv"`) {
+		t.Errorf("csv: Content-Disposition = %q", got)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 || lines[0] != strings.Join(exportColumns, ",") {
+		t.Fatalf("csv: body %s", rec.Body)
+	}
+	for i, want := range []string{",Netflix,999.99,USD,monthly,user123,01-2025,,1", ",Spotify,299.00,RUB,monthly,user123,02-2025,12-2025,1"} {
+		if !strings.HasSuffix(lines[i+1], want) {
+			t.Errorf("csv: line %d = %q, want suffix %q", i+2, lines[i+1], want)
+		}
+	}
+
+	rec = exportRequest(handler, "/api/v2/subscriptions/export?service_name=Apple", "application/x-ndjson")
+	if rec.Code != http.StatusOK || !strings.HasSuffix(rec.Header().Get("Content-Disposition"), `.ndjson"`) {
+		t.Fatalf("ndjson: status %d, headers %v", rec.Code, rec.Header())
+	}
+	var subs []models.Subscription
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var sub models.Subscription
+		if err := json.Unmarshal(scanner.Bytes(), &sub); err != nil {
+			t.Fatalf("ndjson: line %q: %v", scanner.Text(), err)
+		}
+		subs = append(subs, sub)
+	}
+	if len(subs) != 1 || subs[0].ServiceName != "Apple" || subs[0].UserId != "user456" {
+		t.Errorf("ndjson: subscriptions %+v", subs)
+	}
+}
+
+func TestExportSubscriptionsErrors(t *testing.T) {
+	handler := newTestServer(t)
+
+	if rec := exportRequest(handler, "/api/v2/subscriptions/export", "application/xml"); rec.Code != http.StatusNotAcceptable {
+		t.Errorf("xml: status %d, want %d", rec.Code, http.StatusNotAcceptable)
+	}
+	if rec := exportRequest(handler, "/api/v2/subscriptions/export?billing_period=daily", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad filter: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	// an empty export is a CSV with only the header row
+	rec := exportRequest(handler, "/api/v2/subscriptions/export", "text/csv")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != strings.Join(exportColumns, ",") {
+		t.Errorf("empty: status %d, body %q", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/logger"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotentBodySize limits the body read into memory to be hashed, a
+	// bulk create of the maximum size fits in it.
+	maxIdempotentBodySize = 4 << 20
+)
+
+// replayedHeaders are the response headers saved with an idempotent response.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// recordingWriter holds the response body back until it is saved to the
+// idempotency store, so that a failed save can still change the response.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *recordingWriter) WriteString(data string) (int, error) {
+	return w.body.WriteString(data)
+}
+
+// IdempotencyMiddleware makes a route safe to retry with an Idempotency-Key
+// header: the first request with a key is handled and its response saved, a
+// retry with the same key and body gets that response replayed and a reuse of
+// the key with another body is rejected. Server errors are not saved, so the
+// request can be retried with the same key. A response that cannot be saved
+// is replaced by a server error and its key stays pending until it expires.
+func IdempotencyMiddleware(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(c, http.StatusRequestEntityTooLarge, "request-too-large", "Request body is too large",
+				fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit), nil)
+			return
+		}
+		if err != nil {
+			writeError(c, fmt.Errorf("error reading request body: %w", err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+		// the same key sent to another resource is another request
+		scope := c.Request.Method + " " + c.Request.URL.Path
+
+		saved, err := s.Service.BeginIdempotentRequest(scope, key, hex.EncodeToString(hash[:]))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		if saved != nil {
+			for name, value := range saved.Headers {
+				c.Header(name, value)
+			}
+			c.Header(idempotentReplayedHeader, "true")
+			c.Abort()
+			c.Status(saved.Status)
+			_, _ = c.Writer.Write(saved.Body)
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		reserved := false
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			// also frees the key when the handler panics
+			if reserved {
+				return
+			}
+			if err := s.Service.AbandonIdempotentRequest(scope, key); err != nil {
+				logger.GetLoggerFromCtx(s.ctx).Error("error releasing idempotency key",
+					zap.String("request_id", c.GetString(requestIdKey)), zap.Error(err))
+			}
+		}()
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.Status() < http.StatusInternalServerError {
+			headers := make(map[string]string)
+			for _, name := range replayedHeaders {
+				if value := writer.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+			err = s.Service.CompleteIdempotentRequest(&models.IdempotencyRecord{
+				Scope:   scope,
+				Key:     key,
+				Status:  writer.Status(),
+				Headers: headers,
+				Body:    writer.body.Bytes(),
+			})
+			// the request has taken effect either way, a key left pending
+			// makes its retries fail instead of repeating it
+			reserved = true
+			if err != nil {
+				for _, name := range replayedHeaders {
+					c.Writer.Header().Del(name)
+				}
+				writeError(c, fmt.Errorf("error saving idempotent response: %w", err))
+				return
+			}
+		}
+		_, _ = c.Writer.Write(writer.body.Bytes())
+	}
+}
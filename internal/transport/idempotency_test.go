@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/service"
+	"TestEffectiveMobile/pkg/logger"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const idempotentBody = `{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"01-2025"}`
+
+// failingSave is a service that cannot save idempotent responses.
+type failingSave struct {
+	*service.SubscriptionService
+}
+
+func (failingSave) CompleteIdempotentRequest(*models.IdempotencyRecord) error {
+	return errors.New("connection reset")
+}
+
+func sendIdempotent(handler http.Handler, target string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	handler := newTestServer(t)
+
+	first := sendIdempotent(handler, "/api/v2/subscriptions", "key-1", idempotentBody)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first: status %d, body %s", first.Code, first.Body)
+	}
+	retry := sendIdempotent(handler, "/api/v2/subscriptions", "key-1", idempotentBody)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry: status %d, body %s, want the first response %s", retry.Code, retry.Body, first.Body)
+	}
+	if retry.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("retry: Idempotent-Replayed header is not set")
+	}
+	if retry.Header().Get("Location") != first.Header().Get("Location") {
+		t.Errorf("retry: Location = %q, want %q", retry.Header().Get("Location"), first.Header().Get("Location"))
+	}
+
+	reused := sendIdempotent(handler, "/api/v2/subscriptions", "key-1", strings.Replace(idempotentBody, "Netflix", "Spotify", 1))
+	if reused.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key: status %d, want %d", reused.Code, http.StatusUnprocessableEntity)
+	}
+
+	other := sendIdempotent(handler, "/api/v2/subscriptions", "key-2", idempotentBody)
+	if other.Code != http.StatusCreated || other.Body.String() == first.Body.String() {
+		t.Errorf("other key: status %d, body %s", other.Code, other.Body)
+	}
+
+	// keys are scoped to the path, the same key sent to v1 creates its own subscription
+	otherPath := sendIdempotent(handler, "/api/v1/create", "key-1", idempotentBody)
+	if otherPath.Code != http.StatusOK || otherPath.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("other path: status %d, headers %v, body %s", otherPath.Code, otherPath.Header(), otherPath.Body)
+	}
+}
+
+func TestIdempotencyBodyTooLarge(t *testing.T) {
+	handler := newTestServer(t)
+
+	body := `{"service_name":"` + strings.Repeat("x", maxIdempotentBodySize) + `"}`
+	rec := sendIdempotent(handler, "/api/v2/subscriptions", "key-1", body)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestIdempotencyInvalidKey(t *testing.T) {
+	handler := newTestServer(t)
+
+	rec := sendIdempotent(handler, "/api/v2/subscriptions", "key\x01", idempotentBody)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestIdempotencySaveFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	cfg := &config.Config{IdempotencyTTL: time.Hour}
+	srv := failingSave{service.NewSubscriptionService(repository.NewMemorySubscriptionRepository(), cfg, ctx)}
+	handler := New(srv, cfg, ctx).httpServer.Handler
+
+	first := sendIdempotent(handler, "/api/v2/subscriptions", "key-1", idempotentBody)
+	if first.Code != http.StatusInternalServerError {
+		t.Fatalf("first: status %d, body %s, want %d", first.Code, first.Body, http.StatusInternalServerError)
+	}
+	if first.Header().Get("Location") != "" || first.Header().Get("ETag") != "" {
+		t.Errorf("first: headers of the unsaved response were sent: %v", first.Header())
+	}
+	// the request was applied, so the key stays pending rather than letting
+	// the retry create a second subscription
+	retry := sendIdempotent(handler, "/api/v2/subscriptions", "key-1", idempotentBody)
+	if retry.Code != http.StatusConflict {
+		t.Errorf("retry: status %d, body %s, want %d", retry.Code, retry.Body, http.StatusConflict)
+	}
+}
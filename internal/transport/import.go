@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/suberrors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// importFileField is the multipart form field of a CSV import.
+const importFileField = "file"
+
+// @Summary Импортирует подписки из CSV
+// @Description Файл передаётся в поле file, первая строка - заголовок со столбцами service_name,price,user_id,start_date
+// @Description и необязательными currency,billing_period,end_date в любом порядке. Цена указывается в основных единицах валюты, например 399.99.
+// @Description Каждая строка проверяется как при создании подписки, строки с ошибками пропускаются и перечисляются в errors,
+// @Description остальные создаются одним пакетом. С dry_run=true подписки только проверяются.
+// @Tags Пакетные операции
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV-файл с подписками (не более 10000 строк)"
+// @Param dry_run query bool false "Только проверить строки, не создавая подписки" default(false)
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Success 200 {object} models.ImportReport "Результат проверки в режиме dry_run"
+// @Success 201 {object} models.ImportReport "Все строки импортированы"
+// @Success 207 {object} models.ImportReport "Часть строк импортирована, ошибки остальных - в errors"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса или файла, в errors перечислены ошибочные поля"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.ImportReport "Ни одна строка не импортирована, ошибки строк - в errors"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/import [post]
+func ImportSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var options models.ImportOptions
+		if err := bindQuery(c, &options); err != nil {
+			writeError(c, err)
+			return
+		}
+		header, err := c.FormFile(importFileField)
+		if err != nil {
+			verr := &suberrors.ValidationError{}
+			verr.Add(importFileField, suberrors.CodeRequired, "file is required as multipart/form-data field "+importFileField)
+			writeError(c, verr)
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		defer file.Close()
+		report, err := s.Service.ImportSubscriptions(file, options.DryRun, auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		status := http.StatusOK
+		switch {
+		case report.DryRun:
+		case report.Failed == 0:
+			status = http.StatusCreated
+		case report.Imported > 0:
+			status = http.StatusMultiStatus
+		default:
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, report)
+	}
+}
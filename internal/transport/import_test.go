@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func uploadImport(t *testing.T, handler http.Handler, target string, csv string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(importFileField, "subscriptions.csv")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+	part.Write([]byte(csv))
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	const csv = "service_name,price,user_id,start_date\nNetflix,399.99,user123,01-2025\nSpotify,-1,user123,01-2025\n"
+	handler := newTestServer(t)
+
+	rec := uploadImport(t, handler, "/api/v2/subscriptions/import?dry_run=true", csv)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dry run: status %d, body %s", rec.Code, rec.Body)
+	}
+	rec = uploadImport(t, handler, "/api/v2/subscriptions/import", csv)
+	var report models.ImportReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil || rec.Code != http.StatusMultiStatus {
+		t.Fatalf("import: status %d, body %s", rec.Code, rec.Body)
+	}
+	if report.Imported != 1 || report.Failed != 1 || len(report.Errors) != 1 || report.Errors[0].Line != 3 {
+		t.Errorf("import: report %+v", report)
+	}
+
+	var list models.ListSubscriptionsResponse
+	if rec := serve(t, handler, http.MethodGet, "/api/v2/subscriptions?user_id=user123", "", &list); rec.Code != http.StatusOK {
+		t.Fatalf("list: status %d, body %s", rec.Code, rec.Body)
+	}
+	if len(list.Subscriptions) != 1 || list.Subscriptions[0].Price.Amount != 39999 {
+		t.Errorf("list: %+v, want only Netflix imported once", list.Subscriptions)
+	}
+}
+
+func TestImportSubscriptionsWithoutFile(t *testing.T) {
+	handler := newTestServer(t)
+	rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions/import", `{}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
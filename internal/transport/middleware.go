@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/logger"
+	"crypto/subtle"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	requestIdHeader = "X-Request-ID"
+	requestIdKey    = "request_id"
+	actorHeader     = "X-Actor"
+	anonymousActor  = "anonymous"
+	maxActorLength  = 255
+)
+
+// RequestIdMiddleware propagates the caller's X-Request-ID or assigns a new
+// one, so that error documents and logs can be correlated.
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(requestIdHeader)
+		if requestId == "" {
+			requestId = uuid.New().String()
+		}
+		c.Set(requestIdKey, requestId)
+		c.Header(requestIdHeader, requestId)
+		c.Next()
+	}
+}
+
+// auditMeta identifies the caller of a mutating request for the audit log by
+// its X-Actor header and request id.
+func auditMeta(c *gin.Context) *models.AuditMeta {
+	actor := strings.TrimSpace(c.GetHeader(actorHeader))
+	if actor == "" {
+		actor = anonymousActor
+	}
+	if runes := []rune(actor); len(runes) > maxActorLength {
+		actor = string(runes[:maxActorLength])
+	}
+	return &models.AuditMeta{Actor: actor, RequestId: c.GetString(requestIdKey)}
+}
+
+// RecoveryMiddleware renders panics in handlers as a 500 problem document.
+func RecoveryMiddleware(s *SubscriptionServer) gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, rec any) {
+		logger.GetLoggerFromCtx(s.ctx).Error("panic in handler",
+			zap.String("request_id", c.GetString(requestIdKey)),
+			zap.String("panic", fmt.Sprint(rec)))
+		writeProblem(c, http.StatusInternalServerError, "internal-error", "Internal server error", "", nil)
+	})
+}
+
+// DeprecationMiddleware marks every response of a deprecated API version with
+// the Deprecation (RFC 9745) and Sunset (RFC 8594) headers and a link to the
+// successor version.
+func DeprecationMiddleware(deprecatedAt time.Time, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	link := fmt.Sprintf("<%s>; rel=\"successor-version\"", successor)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunsetDate)
+		c.Header("Link", link)
+		c.Next()
+	}
+}
+
+// AdminMiddleware requires the bearer token of the admin routes. Without a
+// token the routes are disabled unless open is set for local runs.
+func AdminMiddleware(token string, open bool) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if token == "" && open {
+			c.Next()
+			return
+		}
+		if token == "" {
+			writeProblem(c, http.StatusServiceUnavailable, "admin-disabled", "Admin routes are disabled",
+				"ADMIN_TOKEN is not configured", nil)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			writeProblem(c, http.StatusUnauthorized, "unauthorized", "Unauthorized", "admin token is missing or invalid", nil)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+// The ETag of a subscription is its version, a write with If-Match is
+// applied only to the version the client has seen.
+
+// setETag sets the ETag of a subscription returned in the response.
+func setETag(c *gin.Context, sub *models.Subscription) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(sub.Version, 10)))
+}
+
+// ifMatch parses the If-Match header, nil means no header was sent. Weak and
+// malformed entity tags never match, as If-Match requires strong comparison.
+func ifMatch(c *gin.Context) *models.VersionMatch {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return nil
+	}
+	if header == "*" {
+		return &models.VersionMatch{Any: true}
+	}
+	match := &models.VersionMatch{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		match.Versions = append(match.Versions, version)
+	}
+	return match
+}
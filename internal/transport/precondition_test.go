@@ -0,0 +1,91 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   *models.VersionMatch
+	}{
+		{name: "no header", header: "", want: nil},
+		{name: "blank", header: "  ", want: nil},
+		{name: "any", header: "*", want: &models.VersionMatch{Any: true}},
+		{name: "single", header: `"3"`, want: &models.VersionMatch{Versions: []int64{3}}},
+		{name: "list", header: `"1", "2"`, want: &models.VersionMatch{Versions: []int64{1, 2}}},
+		{name: "weak", header: `W/"1"`, want: &models.VersionMatch{}},
+		{name: "weak in list", header: `W/"1", "2"`, want: &models.VersionMatch{Versions: []int64{2}}},
+		{name: "unquoted", header: `3`, want: &models.VersionMatch{}},
+		{name: "not a version", header: `"abc"`, want: &models.VersionMatch{}},
+		{name: "empty tag", header: `""`, want: &models.VersionMatch{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPatch, "/api/v2/subscriptions/1", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+			if got := ifMatch(c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ifMatch(%q) = %+v, want %+v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchIfMatch(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		handler := newTestServerWith(t, &config.Config{RequireIfMatch: strict})
+		var created models.Subscription
+		rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions",
+			`{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"user123","start_date":"01-2025"}`, &created)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+		}
+		patch := func(ifMatch string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPatch, "/api/v2/subscriptions/"+created.Id, strings.NewReader(`{"service_name":"Netflix Premium"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+
+		want := http.StatusOK
+		if strict {
+			want = http.StatusPreconditionRequired
+		}
+		if rec := patch(""); rec.Code != want {
+			t.Errorf("strict %v: patch without If-Match: status %d, want %d", strict, rec.Code, want)
+		}
+		version := 1
+		if !strict {
+			version = 2
+		}
+		current := `"` + strconv.Itoa(version) + `"`
+		stale := `"` + strconv.Itoa(version-1) + `"`
+		if rec := patch(stale); rec.Code != http.StatusPreconditionFailed {
+			t.Errorf("strict %v: patch with a stale If-Match: status %d, want %d", strict, rec.Code, http.StatusPreconditionFailed)
+		}
+		rec = patch(current)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("strict %v: patch with If-Match %s: status %d, body %s", strict, current, rec.Code, rec.Body)
+		}
+		if etag := rec.Header().Get("ETag"); etag != `"`+strconv.Itoa(version+1)+`"` {
+			t.Errorf("strict %v: patch: ETag = %q, want version %d", strict, etag, version+1)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// @Summary Планирует изменение цены подписки
+// @Description Новая цена действует с месяца effective_from до следующего изменения, суммы за прошлые месяцы считаются по прежней цене.
+// @Description Изменение в том же месяце перезаписывается, без валюты сохраняется текущая валюта подписки.
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Param input body models.PriceChange true "Новая цена и месяц, с которого она действует"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Success 201 {object} models.PriceChange "Запланированное изменение цены"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.BadResponse "Нарушено ограничение целостности"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id}/prices [post]
+func SchedulePriceChangeHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var request *models.PriceChange
+		if err := bindJSON(c, &request); err != nil {
+			writeError(c, err)
+			return
+		}
+		change, err := s.Service.SchedulePriceChange(id, request, auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, change)
+	}
+}
+
+// @Summary Возвращает историю цен подписки
+// @Description Первая запись - цена с месяца начала подписки, далее изменения цены, включая запланированные
+// @Tags Подписки
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Success 200 {object} models.PriceHistoryResponse "История цен"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id}/prices [get]
+func ListPricesHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prices, err := s.Service.ListPrices(c.Param("id"))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, models.PriceHistoryResponse{Prices: prices})
+	}
+}
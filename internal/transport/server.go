@@ -6,43 +6,105 @@ import (
 	"TestEffectiveMobile/internal/models"
 	"TestEffectiveMobile/internal/service"
 	"TestEffectiveMobile/pkg/logger"
-	"TestEffectiveMobile/pkg/suberrors"
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"net/http"
+	"time"
+)
+
+// The v1 verb-named routes are kept as a deprecated alias of v2.
+var (
+	v1DeprecatedAt = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+	v1Sunset       = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
 )
 
 type SubscriptionServer struct {
-	Service service.SubscriptionServiceInterface
-	cfg     *config.Config
-	ctx     context.Context
+	Service    service.SubscriptionServiceInterface
+	cfg        *config.Config
+	ctx        context.Context
+	httpServer *http.Server
 }
 
 func New(srv service.SubscriptionServiceInterface, cfg *config.Config, ctx context.Context) *SubscriptionServer {
-	return &SubscriptionServer{
+	s := &SubscriptionServer{
 		Service: srv,
 		cfg:     cfg,
 		ctx:     ctx,
 	}
+	s.httpServer = &http.Server{
+		Addr:    cfg.Host + ":" + cfg.Port,
+		Handler: s.router(),
+	}
+	return s
 }
 
+// Run serves HTTP until Shutdown is called.
 func (s *SubscriptionServer) Run() error {
-	router := gin.Default()
 	logger.GetLoggerFromCtx(s.ctx).Info("gin framework is running")
-	api := router.Group("/api/v1")
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting new connections and waits for in-flight requests
+// to finish or for ctx to expire.
+func (s *SubscriptionServer) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+func (s *SubscriptionServer) router() *gin.Engine {
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.Use(gin.Logger(), RequestIdMiddleware(), RecoveryMiddleware(s))
+	router.NoRoute(func(c *gin.Context) {
+		writeProblem(c, http.StatusNotFound, "not-found", "Not found", "no route for "+c.Request.URL.Path, nil)
+	})
+	router.NoMethod(func(c *gin.Context) {
+		writeProblem(c, http.StatusMethodNotAllowed, "method-not-allowed", "Method not allowed", c.Request.Method+" is not allowed for "+c.Request.URL.Path, nil)
+	})
+	v1 := router.Group("/api/v1", DeprecationMiddleware(v1DeprecatedAt, v1Sunset, "/api/v2"))
+	{
+		v1.POST("/create", IdempotencyMiddleware(s), CreateSubscriptionHandler(s))
+		v1.GET("/read/:id", ReadSubscriptionHandler(s))
+		v1.PUT("/update/:id", UpdateSubscriptionHandler(s))
+		v1.PATCH("/subscriptions/:id", PatchSubscriptionHandler(s))
+		v1.DELETE("/delete/:id", DeleteSubscriptionHandler(s))
+		v1.GET("/list/:user_id", ListSubscriptionsHandler(s))
+		v1.GET("/sum", CalculateSumSubscriptionsHandler(s))
+	}
+	v2 := router.Group("/api/v2")
 	{
-		api.POST("/create", CreateSubscriptionHandler(s))
-		api.GET("/read/:id", ReadSubscriptionHandler(s))
-		api.PUT("/update/:id", UpdateSubscriptionHandler(s))
-		api.DELETE("/delete/:id", DeleteSubscriptionHandler(s))
-		api.GET("/list/:user_id", ListSubscriptionsHandler(s))
-		api.GET("/sum", CalculateSumSubscriptionsHandler(s))
+		v2.POST("/subscriptions", IdempotencyMiddleware(s), CreateSubscriptionV2Handler(s))
+		v2.GET("/subscriptions", SearchSubscriptionsHandler(s))
+		v2.POST("/subscriptions/bulk/create", IdempotencyMiddleware(s), BulkCreateSubscriptionsHandler(s))
+		v2.POST("/subscriptions/bulk/update", BulkUpdateSubscriptionsHandler(s))
+		v2.POST("/subscriptions/bulk/delete", BulkDeleteSubscriptionsHandler(s))
+		v2.POST("/subscriptions/import", ImportSubscriptionsHandler(s))
+		v2.GET("/subscriptions/export", ExportSubscriptionsHandler(s))
+		v2.GET("/subscriptions/:id", ReadSubscriptionV2Handler(s))
+		v2.PUT("/subscriptions/:id", ReplaceSubscriptionHandler(s))
+		v2.PATCH("/subscriptions/:id", PatchSubscriptionV2Handler(s))
+		v2.DELETE("/subscriptions/:id", DeleteSubscriptionV2Handler(s))
+		v2.POST("/subscriptions/:id/prices", SchedulePriceChangeHandler(s))
+		v2.GET("/subscriptions/:id/prices", ListPricesHandler(s))
+		v2.GET("/subscriptions/:id/history", SubscriptionHistoryHandler(s))
+		v2.POST("/subscriptions/:id/restore", RestoreSubscriptionHandler(s))
+		v2.GET("/users/:user_id/subscriptions", ListSubscriptionsV2Handler(s))
+		v2.GET("/reports/sum", CalculateSumSubscriptionsV2Handler(s))
+		v2.GET("/reports/monthly", MonthlyReportHandler(s))
+		v2.GET("/reports/aggregate", AggregateSubscriptionsHandler(s))
+	}
+	admin := router.Group("/api/v2/admin", AdminMiddleware(s.cfg.AdminToken, s.cfg.AdminOpen))
+	{
+		admin.POST("/exchange-rates", UploadExchangeRatesHandler(s))
+		admin.GET("/exchange-rates", ListExchangeRatesHandler(s))
 	}
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	return router.Run(s.cfg.Host + ":" + s.cfg.Port)
+	return router
 }
 
 // @Summary Создаёт новую подписку
@@ -50,31 +112,27 @@ func (s *SubscriptionServer) Run() error {
 // @Accept json
 // @Produce json
 // @Param input body models.CreateSubscription true "Данные для создания подписки"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param Idempotency-Key header string false "Ключ идемпотентности: повтор запроса с тем же ключом и телом возвращает исходный ответ" example(3f1c2a9e-7b4d-4e0a-9c55-1d2e3f4a5b6c)
 // @Success 200 {object} models.ID "Id созданной подписки"
-// @Failure 400 {object} models.BadResponse "Неверный формат запроса"
+// @Header 200 {string} Idempotent-Replayed "true, если ответ повторён по ключу идемпотентности"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
 // @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Подписка уже существует или запрос с тем же ключом идемпотентности ещё выполняется"
+// @Failure 413 {object} models.BadResponse "Тело запроса с ключом идемпотентности больше 4 МиБ"
+// @Failure 422 {object} models.BadResponse "Нарушено ограничение целостности или ключ идемпотентности использован с другим телом запроса"
 // @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
-// @Router /create [post]
+// @DeprecatedRouter /v1/create [post]
 func CreateSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error1"})
-				return
-			}
-		}()
-		if c.Request.Method != http.MethodPost {
-			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
-			return
-		}
 		var request *models.Subscription
-		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error2"})
+		if err := bindJSON(c, &request); err != nil {
+			writeError(c, err)
 			return
 		}
-		id, err := s.Service.Create(request)
+		id, err := s.Service.Create(request, auditMeta(c))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error3"})
+			writeError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, models.ID{Id: id})
@@ -86,42 +144,24 @@ func CreateSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
-// @Success 200 {object} models.Subscription "Подписка"
-// @Failure 400 {object} models.BadResponse "Неверный формат запроса"
+// @Description Цена возвращается целым числом в основных единицах валюты, копейки отбрасываются
+// @Success 200 {object} models.SubscriptionV1 "Подписка"
+// @Header 200 {string} ETag "Версия подписки"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
 // @Failure 404 {object} models.BadResponse "Подписка не найдена"
 // @Failure 405 {object} models.BadResponse "Метод не разрешён"
 // @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
-// @Router /read/{id} [get]
+// @DeprecatedRouter /v1/read/{id} [get]
 func ReadSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error1"})
-				return
-			}
-		}()
-		if c.Request.Method != http.MethodGet {
-			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
-			return
-		}
 		id := c.Param("id")
 		sub, err := s.Service.Read(id)
 		if err != nil {
-			if errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Subscription id not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error2"})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, models.Subscription{
-			ServiceName: sub.ServiceName,
-			Price:       sub.Price,
-			Id:          id,
-			UserId:      sub.UserId,
-			StartDate:   sub.StartDate,
-			EndDate:     sub.EndDate,
-		})
+		setETag(c, sub)
+		c.JSON(http.StatusOK, subscriptionV1(sub))
 	}
 }
 
@@ -131,74 +171,97 @@ func ReadSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
 // @Produce json
 // @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
 // @Param input body models.UpdateSubscription true "Данные для обновления подписки"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param If-Match header string false "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии" example("1")
 // @Success 200 {object} models.GoodResponse "Подписка обновлена"
-// @Failure 400 {object} models.BadResponse "Неверный формат запроса"
+// @Header 200 {string} ETag "Версия подписки"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
 // @Failure 404 {object} models.BadResponse "Подписка не найдена"
 // @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Конфликт с существующей подпиской"
+// @Failure 412 {object} models.BadResponse "Подписка изменена другим запросом, ETag не совпадает"
+// @Failure 422 {object} models.BadResponse "Нарушено ограничение целостности"
+// @Failure 428 {object} models.BadResponse "Не передан заголовок If-Match, когда он обязателен"
 // @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
-// @Router /update/{id} [put]
+// @DeprecatedRouter /v1/update/{id} [put]
 func UpdateSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error1"})
-				return
-			}
-		}()
-		if c.Request.Method != http.MethodPut {
-			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
+		id := c.Param("id")
+		var request *models.UpdateSubscription
+		if err := bindJSON(c, &request); err != nil {
+			writeError(c, err)
 			return
 		}
+		sub, err := s.Service.Update(id, request, ifMatch(c), auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		setETag(c, sub)
+		c.JSON(http.StatusOK, models.GoodResponse{Message: "Updated"})
+	}
+}
+
+// @Summary Частично обновляет подписку по id
+// @Description Изменяются только переданные поля, пустая строка в end_date делает подписку бессрочной
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Param input body models.UpdateSubscription true "Поля подписки для изменения"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param If-Match header string false "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии" example("1")
+// @Success 200 {object} models.SubscriptionV1 "Обновлённая подписка, цена в основных единицах валюты"
+// @Header 200 {string} ETag "Версия подписки"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Конфликт с существующей подпиской"
+// @Failure 412 {object} models.BadResponse "Подписка изменена другим запросом, ETag не совпадает"
+// @Failure 422 {object} models.BadResponse "Нарушено ограничение целостности"
+// @Failure 428 {object} models.BadResponse "Не передан заголовок If-Match, когда он обязателен"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @DeprecatedRouter /v1/subscriptions/{id} [patch]
+func PatchSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
 		id := c.Param("id")
 		var request *models.UpdateSubscription
-		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error2"})
+		if err := bindJSON(c, &request); err != nil {
+			writeError(c, err)
 			return
 		}
-		err := s.Service.Update(id, request)
+		sub, err := s.Service.Patch(id, request, ifMatch(c), auditMeta(c))
 		if err != nil {
-			if errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Subscription id not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error3", "message": err.Error()})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, models.GoodResponse{Message: "Updated"})
+		setETag(c, sub)
+		c.JSON(http.StatusOK, subscriptionV1(sub))
 	}
 }
 
 // @Summary Удаляет подписку по id
+// @Description Подписка помечается удалённой и не попадает в выдачу и суммы, её можно восстановить до очистки командой purge
 // @Tags Подписки
 // @Accept json
 // @Produce json
 // @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param If-Match header string false "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии" example("1")
 // @Success 200 {object} models.GoodResponse "Подписка удалена"
-// @Failure 400 {object} models.BadResponse "Неверный формат запроса"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
 // @Failure 404 {object} models.BadResponse "Подписка не найдена"
 // @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 412 {object} models.BadResponse "Подписка изменена другим запросом, ETag не совпадает"
+// @Failure 428 {object} models.BadResponse "Не передан заголовок If-Match, когда он обязателен"
 // @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
-// @Router /delete/{id} [delete]
+// @DeprecatedRouter /v1/delete/{id} [delete]
 func DeleteSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error1"})
-				return
-			}
-		}()
-		if c.Request.Method != http.MethodDelete {
-			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
-			return
-		}
 		id := c.Param("id")
-		err := s.Service.Delete(id)
+		err := s.Service.Delete(id, ifMatch(c), auditMeta(c))
 		if err != nil {
-			if errors.Is(err, suberrors.ErrIdSubscriptionNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Subscription id not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error2"})
+			writeError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, models.GoodResponse{Message: "Deleted"})
@@ -210,35 +273,21 @@ func DeleteSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param user_id path string true "ID пользователя" example("user12345")
-// @Success 200 {object} models.ListSubscriptionsResponse "Список подписок пользователя"
-// @Failure 400 {object} models.BadResponse "Неверный формат запроса"
+// @Success 200 {object} models.ListSubscriptionsV1Response "Список подписок пользователя, цены в основных единицах валюты"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
 // @Failure 404 {object} models.BadResponse "Пользователь не найден"
 // @Failure 405 {object} models.BadResponse "Метод не разрешён"
 // @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
-// @Router /list/{user_id} [get]
+// @DeprecatedRouter /v1/list/{user_id} [get]
 func ListSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error1"})
-				return
-			}
-		}()
-		if c.Request.Method != http.MethodGet {
-			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
-			return
-		}
 		userId := c.Param("user_id")
 		subs, err := s.Service.ListSubscriptions(userId)
 		if err != nil {
-			if errors.Is(err, suberrors.ErrUserIdNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "User id not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error2"})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, models.ListSubscriptionsResponse{Subscriptions: subs})
+		c.JSON(http.StatusOK, subscriptionsV1(subs))
 	}
 }
 
@@ -250,37 +299,30 @@ func ListSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
 // @Param start_date query string true "Дата начала периода" format(date) example(01-2006)
 // @Param end_date query string true "Дата окончания периода" format(date) example(01-2006)
 // @Param service_name query string true "Название сервиса" example("YouTube")
-// @Success 200 {object} models.SumSubscriptionsResponse "Сумма подписок пользователя"
-// @Failure 400 {object} models.BadResponse "Неверный формат запроса"
+// @Param currency query string false "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)" example(RUB)
+// @Param mode query string false "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам" Enums(charged, amortized) default(charged)
+// @Description Стоимость каждой подписки умножается на число месяцев её пересечения с периодом [start_date, end_date].
+// @Description Сумма возвращается целым числом в основных единицах валюты, копейки отбрасываются. Подписки в разных валютах складываются только
+// @Description при переданном currency: стоимость каждого месяца пересчитывается по курсу, действующему в этом месяце.
+// @Success 200 {object} models.SumSubscriptionsV1Response "Сумма подписок пользователя и число оплачиваемых месяцев"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
 // @Failure 404 {object} models.BadResponse "Пользователь не найден"
 // @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.BadResponse "Подписки оплачиваются в разных валютах или нет курса для пересчёта"
 // @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
-// @Router /sum [get]
+// @DeprecatedRouter /v1/sum [get]
 func CalculateSumSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error1"})
-				return
-			}
-		}()
-		if c.Request.Method != http.MethodGet {
-			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
+		var filter models.ReportFilter
+		if err := bindQuery(c, &filter); err != nil {
+			writeError(c, err)
 			return
 		}
-		startDate := c.Query("start_date")
-		endDate := c.Query("end_date")
-		userID := c.Query("user_id")
-		nameService := c.Query("service_name")
-		sum, err := s.Service.CalculateSumSubscriptions(userID, startDate, endDate, nameService)
+		sum, err := s.Service.CalculateSumSubscriptions(&filter)
 		if err != nil {
-			if errors.Is(err, suberrors.ErrUserIdNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "User id not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error2", "message": err.Error()})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, models.SumSubscriptionsResponse{Sum: sum})
+		c.JSON(http.StatusOK, sumV1(sum))
 	}
 }
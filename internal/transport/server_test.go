@@ -0,0 +1,206 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/config"
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/internal/repository"
+	"TestEffectiveMobile/internal/service"
+	"TestEffectiveMobile/pkg/logger"
+	"TestEffectiveMobile/pkg/money"
+	"TestEffectiveMobile/pkg/suberrors"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testAdminToken is the bearer token of the admin routes of newTestServer.
+const testAdminToken = "secret"
+
+// newTestServer returns the router of a server on the memory backend.
+func newTestServer(t *testing.T) http.Handler {
+	return newTestServerWith(t, &config.Config{AdminToken: testAdminToken, IdempotencyTTL: time.Hour})
+}
+
+// newTestServerWith is newTestServer with the given configuration.
+func newTestServerWith(t *testing.T, cfg *config.Config) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	srv := service.NewSubscriptionService(repository.NewMemorySubscriptionRepository(), cfg, ctx)
+	return New(srv, cfg, ctx).httpServer.Handler
+}
+
+// serve sends a request to handler and decodes the JSON response into dst
+// unless dst is nil.
+func serve(t *testing.T, handler http.Handler, method string, target string, body string, dst any) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if dst != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), dst); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, target, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+func TestV1CreateReadSum(t *testing.T) {
+	handler := newTestServer(t)
+
+	var created models.ID
+	rec := serve(t, handler, http.MethodPost, "/api/v1/create",
+		`{"service_name":"Yandex Plus","price":400,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"07-2025","end_date":"09-2025"}`, &created)
+	if rec.Code != http.StatusOK || created.Id == "" {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Deprecation") == "" || rec.Header().Get("Sunset") == "" {
+		t.Errorf("create: Deprecation and Sunset headers are not set: %v", rec.Header())
+	}
+
+	var sub models.SubscriptionV1
+	rec = serve(t, handler, http.MethodGet, "/api/v1/read/"+created.Id, "", &sub)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("read: status %d, body %s", rec.Code, rec.Body)
+	}
+	want := models.SubscriptionV1{
+		ServiceName:   "Yandex Plus",
+		Price:         400,
+		Currency:      money.DefaultCurrency,
+		BillingPeriod: models.BillingMonthly,
+		Id:            created.Id,
+		UserId:        "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		StartDate:     "07-2025",
+		EndDate:       "09-2025",
+		Version:       1,
+	}
+	if sub != want {
+		t.Errorf("read = %+v, want %+v", sub, want)
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("read: ETag = %q, want %q", etag, `"1"`)
+	}
+
+	var sum models.SumSubscriptionsV1Response
+	rec = serve(t, handler, http.MethodGet, "/api/v1/sum?start_date=01-2025&end_date=12-2025&service_name=Yandex+Plus", "", &sum)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("sum: status %d, body %s", rec.Code, rec.Body)
+	}
+	if want := (models.SumSubscriptionsV1Response{Sum: 1200, Currency: money.DefaultCurrency, Months: 3}); sum != want {
+		t.Errorf("sum = %+v, want %+v", sum, want)
+	}
+}
+
+func TestV2CreateReadSum(t *testing.T) {
+	handler := newTestServer(t)
+
+	var created models.Subscription
+	rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions",
+		`{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"01-2025"}`, &created)
+	if rec.Code != http.StatusCreated || created.Id == "" {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	if location := rec.Header().Get("Location"); location != "/api/v2/subscriptions/"+created.Id {
+		t.Errorf("create: Location = %q", location)
+	}
+	if rec.Header().Get("Deprecation") != "" {
+		t.Errorf("create: v2 response is marked as deprecated")
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("create: ETag = %q, want %q", etag, `"1"`)
+	}
+
+	var sub models.Subscription
+	rec = serve(t, handler, http.MethodGet, "/api/v2/subscriptions/"+created.Id, "", &sub)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("read: status %d, body %s", rec.Code, rec.Body)
+	}
+	if sub != created {
+		t.Errorf("read = %+v, want %+v", sub, created)
+	}
+
+	var sum models.SumSubscriptionsResponse
+	rec = serve(t, handler, http.MethodGet, "/api/v2/reports/sum?start_date=01-2025&end_date=03-2025", "", &sum)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("sum: status %d, body %s", rec.Code, rec.Body)
+	}
+	if want := (models.SumSubscriptionsResponse{Sum: 3 * 99999, Currency: "USD", Months: 3}); sum != want {
+		t.Errorf("sum = %+v, want %+v", sum, want)
+	}
+}
+
+func TestV2ReadNotFound(t *testing.T) {
+	handler := newTestServer(t)
+
+	var problem models.BadResponse
+	rec := serve(t, handler, http.MethodGet, "/api/v2/subscriptions/550e8400-e29b-41d4-a716-446655440000", "", &problem)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("read: status %d, body %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+		t.Errorf("read: Content-Type = %q", ct)
+	}
+	if problem.Status != http.StatusNotFound || problem.Instance != "/api/v2/subscriptions/550e8400-e29b-41d4-a716-446655440000" {
+		t.Errorf("read: problem = %+v", problem)
+	}
+}
+
+func TestV2CreateValidation(t *testing.T) {
+	handler := newTestServer(t)
+
+	var problem models.BadResponse
+	rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions", `{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"user123"}`, &problem)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "start_date" {
+		t.Errorf("create: errors = %+v, want start_date", problem.Errors)
+	}
+
+	for body, code := range map[string]string{
+		`{"service_name":"Netflix","price":"400","user_id":"user123","start_date":"01-2025"}`:             suberrors.CodeInvalidType,
+		`{"service_name":"Netflix","price":92233720368547759,"user_id":"user123","start_date":"01-2025"}`: suberrors.CodeOutOfRange,
+	} {
+		problem = models.BadResponse{}
+		rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions", body, &problem)
+		if rec.Code != http.StatusBadRequest || len(problem.Errors) != 1 || problem.Errors[0].Field != "price" || problem.Errors[0].Code != code {
+			t.Errorf("create %s: status %d, errors = %+v, want price %s", body, rec.Code, problem.Errors, code)
+		}
+	}
+}
+
+func TestV1UpdateLegacyPriceKeepsCurrency(t *testing.T) {
+	handler := newTestServer(t)
+
+	var created models.Subscription
+	rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions",
+		`{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"user123","start_date":"01-2025"}`, &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	rec = serve(t, handler, http.MethodPut, "/api/v1/update/"+created.Id,
+		`{"service_name":"Netflix","price":1500,"user_id":"user123","start_date":"01-2025"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	var sub models.Subscription
+	if rec := serve(t, handler, http.MethodGet, "/api/v2/subscriptions/"+created.Id, "", &sub); rec.Code != http.StatusOK {
+		t.Fatalf("read: status %d, body %s", rec.Code, rec.Body)
+	}
+	if want := (money.Money{Amount: 150000, Currency: "USD"}); sub.Price != want {
+		t.Errorf("read: price = %+v, want %+v", sub.Price, want)
+	}
+}
@@ -0,0 +1,41 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"net/http"
+	"testing"
+)
+
+func TestDeleteRestore(t *testing.T) {
+	handler := newTestServer(t)
+
+	var created models.Subscription
+	rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions",
+		`{"service_name":"Netflix","price":{"amount":99999,"currency":"USD"},"user_id":"user123","start_date":"01-2025"}`, &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	target := "/api/v2/subscriptions/" + created.Id
+
+	if rec := serve(t, handler, http.MethodDelete, target, "", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serve(t, handler, http.MethodGet, target, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("read of a deleted subscription: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	var restored models.Subscription
+	rec = serve(t, handler, http.MethodPost, target+"/restore", "", &restored)
+	if rec.Code != http.StatusOK || restored.Id != created.Id {
+		t.Fatalf("restore: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serve(t, handler, http.MethodGet, target, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("read of a restored subscription: status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(t, handler, http.MethodPost, target+"/restore", "", nil); rec.Code != http.StatusConflict {
+		t.Errorf("restore of an active subscription: status %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := serve(t, handler, http.MethodPost, "/api/v2/subscriptions/550e8400-e29b-41d4-a716-446655440000/restore", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("restore of an unknown subscription: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"TestEffectiveMobile/pkg/money"
+)
+
+// subscriptionV1 renders sub with the legacy integer price of the v1 API.
+func subscriptionV1(sub *models.Subscription) *models.SubscriptionV1 {
+	return &models.SubscriptionV1{
+		ServiceName:   sub.ServiceName,
+		Price:         money.MajorUnits(sub.Price.Amount),
+		Currency:      sub.Price.Currency,
+		BillingPeriod: sub.BillingPeriod,
+		Id:            sub.Id,
+		UserId:        sub.UserId,
+		StartDate:     sub.StartDate,
+		EndDate:       sub.EndDate,
+		Version:       sub.Version,
+	}
+}
+
+func subscriptionsV1(subs []*models.Subscription) *models.ListSubscriptionsV1Response {
+	response := &models.ListSubscriptionsV1Response{Subscriptions: make([]*models.SubscriptionV1, 0, len(subs))}
+	for _, sub := range subs {
+		response.Subscriptions = append(response.Subscriptions, subscriptionV1(sub))
+	}
+	return response
+}
+
+func sumV1(sum *models.SumSubscriptionsResponse) *models.SumSubscriptionsV1Response {
+	return &models.SumSubscriptionsV1Response{
+		Sum:      money.MajorUnits(sum.Sum),
+		Currency: sum.Currency,
+		Months:   sum.Months,
+	}
+}
@@ -0,0 +1,394 @@
+package transport
+
+import (
+	"TestEffectiveMobile/internal/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// @Summary Создаёт новую подписку
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param input body models.CreateSubscription true "Данные для создания подписки"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param Idempotency-Key header string false "Ключ идемпотентности: повтор запроса с тем же ключом и телом возвращает исходный ответ" example(3f1c2a9e-7b4d-4e0a-9c55-1d2e3f4a5b6c)
+// @Success 201 {object} models.Subscription "Созданная подписка"
+// @Header 201 {string} Location "Адрес созданной подписки"
+// @Header 201 {string} ETag "Версия подписки"
+// @Header 201 {string} Idempotent-Replayed "true, если ответ повторён по ключу идемпотентности"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Подписка уже существует или запрос с тем же ключом идемпотентности ещё выполняется"
+// @Failure 413 {object} models.BadResponse "Тело запроса с ключом идемпотентности больше 4 МиБ"
+// @Failure 422 {object} models.BadResponse "Нарушено ограничение целостности или ключ идемпотентности использован с другим телом запроса"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions [post]
+func CreateSubscriptionV2Handler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request *models.Subscription
+		if err := bindJSON(c, &request); err != nil {
+			writeError(c, err)
+			return
+		}
+		id, err := s.Service.Create(request, auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.Header("Location", "/api/v2/subscriptions/"+id)
+		setETag(c, request)
+		c.JSON(http.StatusCreated, request)
+	}
+}
+
+// @Summary Получает подписку по id
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Success 200 {object} models.Subscription "Подписка"
+// @Header 200 {string} ETag "Версия подписки"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id} [get]
+func ReadSubscriptionV2Handler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		sub, err := s.Service.Read(id)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		setETag(c, sub)
+		c.JSON(http.StatusOK, models.Subscription{
+			ServiceName:   sub.ServiceName,
+			Price:         sub.Price,
+			BillingPeriod: sub.BillingPeriod,
+			Id:            id,
+			UserId:        sub.UserId,
+			StartDate:     sub.StartDate,
+			EndDate:       sub.EndDate,
+			Version:       sub.Version,
+		})
+	}
+}
+
+// @Summary Заменяет подписку по id
+// @Description Передаются все поля подписки, отсутствие end_date делает подписку бессрочной
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Param input body models.UpdateSubscription true "Данные подписки"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param If-Match header string false "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии" example("1")
+// @Success 200 {object} models.Subscription "Обновлённая подписка"
+// @Header 200 {string} ETag "Версия подписки"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Конфликт с существующей подпиской"
+// @Failure 412 {object} models.BadResponse "Подписка изменена другим запросом, ETag не совпадает"
+// @Failure 422 {object} models.BadResponse "Нарушено ограничение целостности"
+// @Failure 428 {object} models.BadResponse "Не передан заголовок If-Match, когда он обязателен"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id} [put]
+func ReplaceSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var request *models.UpdateSubscription
+		if err := bindJSON(c, &request); err != nil {
+			writeError(c, err)
+			return
+		}
+		sub, err := s.Service.Update(id, request, ifMatch(c), auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		setETag(c, sub)
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+// @Summary Частично обновляет подписку по id
+// @Description Изменяются только переданные поля, пустая строка в end_date делает подписку бессрочной
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Param input body models.UpdateSubscription true "Поля подписки для изменения"
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param If-Match header string false "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии" example("1")
+// @Success 200 {object} models.Subscription "Обновлённая подписка"
+// @Header 200 {string} ETag "Версия подписки"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Конфликт с существующей подпиской"
+// @Failure 412 {object} models.BadResponse "Подписка изменена другим запросом, ETag не совпадает"
+// @Failure 422 {object} models.BadResponse "Нарушено ограничение целостности"
+// @Failure 428 {object} models.BadResponse "Не передан заголовок If-Match, когда он обязателен"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id} [patch]
+func PatchSubscriptionV2Handler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var request *models.UpdateSubscription
+		if err := bindJSON(c, &request); err != nil {
+			writeError(c, err)
+			return
+		}
+		sub, err := s.Service.Patch(id, request, ifMatch(c), auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		setETag(c, sub)
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+// @Summary Удаляет подписку по id
+// @Description Подписка помечается удалённой и не попадает в выдачу и суммы, её можно восстановить до очистки командой purge
+// @Tags Подписки
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Param If-Match header string false "ETag подписки из последнего чтения, без заголовка изменение применяется к любой версии" example("1")
+// @Success 204 "Подписка удалена"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 412 {object} models.BadResponse "Подписка изменена другим запросом, ETag не совпадает"
+// @Failure 428 {object} models.BadResponse "Не передан заголовок If-Match, когда он обязателен"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id} [delete]
+func DeleteSubscriptionV2Handler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if err := s.Service.Delete(id, ifMatch(c), auditMeta(c)); err != nil {
+			writeError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// @Summary Возвращает список подписок пользователя
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя" example("user12345")
+// @Success 200 {object} models.ListSubscriptionsResponse "Список подписок пользователя"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Пользователь не найден"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/users/{user_id}/subscriptions [get]
+func ListSubscriptionsV2Handler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+		subs, err := s.Service.ListSubscriptions(userId)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, models.ListSubscriptionsResponse{Subscriptions: subs})
+	}
+}
+
+// @Summary Возвращает страницу подписок с фильтрацией и сортировкой
+// @Description Постраничная выдача по курсору: следующая страница запрашивается с cursor из next_cursor
+// @Tags Подписки
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param min_price query int false "Минимальная цена в минимальных единицах валюты"
+// @Param max_price query int false "Максимальная цена в минимальных единицах валюты"
+// @Param currency query string false "Валюта (ISO 4217)" example(RUB)
+// @Param billing_period query string false "Период оплаты" Enums(weekly, monthly, quarterly, yearly)
+// @Param active_at query string false "Подписка активна в месяце" example(06-2025)
+// @Param start_from query string false "Начало подписки не раньше" example(01-2025)
+// @Param start_to query string false "Начало подписки не позже" example(12-2025)
+// @Param end_from query string false "Окончание подписки не раньше" example(01-2025)
+// @Param end_to query string false "Окончание подписки не позже" example(12-2025)
+// @Param sort query string false "Поле сортировки, - для убывания" Enums(price, -price, start_date, -start_date, service_name, -service_name)
+// @Param limit query int false "Размер страницы (1-100)" default(20)
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} models.ListSubscriptionsResponse "Страница подписок"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions [get]
+func SearchSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter models.SubscriptionFilter
+		if err := bindQuery(c, &filter); err != nil {
+			writeError(c, err)
+			return
+		}
+		page, err := s.Service.SearchSubscriptions(&filter)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// @Summary Возвращает сумму подписок пользователя
+// @Tags Подписки
+// @Accept json
+// @Produce json
+// @Param user_id query string true "ID пользователя" example("user12345")
+// @Param start_date query string true "Дата начала периода" format(date) example(01-2006)
+// @Param end_date query string true "Дата окончания периода" format(date) example(01-2006)
+// @Param service_name query string true "Название сервиса" example("YouTube")
+// @Param currency query string false "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)" example(RUB)
+// @Param mode query string false "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам" Enums(charged, amortized) default(charged)
+// @Description Стоимость каждой подписки умножается на число месяцев её пересечения с периодом [start_date, end_date].
+// @Description Сумма возвращается в минимальных единицах валюты. Подписки в разных валютах складываются только
+// @Description при переданном currency: стоимость каждого месяца пересчитывается по курсу, действующему в этом месяце.
+// @Success 200 {object} models.SumSubscriptionsResponse "Сумма подписок пользователя и число оплачиваемых месяцев"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Пользователь не найден"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.BadResponse "Подписки оплачиваются в разных валютах или нет курса для пересчёта"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/reports/sum [get]
+func CalculateSumSubscriptionsV2Handler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter models.ReportFilter
+		if err := bindQuery(c, &filter); err != nil {
+			writeError(c, err)
+			return
+		}
+		sum, err := s.Service.CalculateSumSubscriptions(&filter)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, sum)
+	}
+}
+
+// @Summary Возвращает помесячную разбивку расходов на подписки
+// @Description Для каждого месяца периода возвращается сумма и разбивка по сервисам, фильтры те же, что у суммы подписок
+// @Tags Отчёты
+// @Produce json
+// @Param user_id query string false "ID пользователя" example("user12345")
+// @Param service_name query string false "Название сервиса" example("YouTube")
+// @Param start_date query string true "Месяц начала периода" example(01-2025)
+// @Param end_date query string true "Месяц окончания периода" example(12-2025)
+// @Param currency query string false "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)" example(RUB)
+// @Param mode query string false "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам" Enums(charged, amortized) default(charged)
+// @Success 200 {object} models.MonthlyReport "Помесячный отчёт"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Пользователь не найден"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.BadResponse "Подписки оплачиваются в разных валютах или нет курса для пересчёта"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/reports/monthly [get]
+func MonthlyReportHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter models.ReportFilter
+		if err := bindQuery(c, &filter); err != nil {
+			writeError(c, err)
+			return
+		}
+		report, err := s.Service.MonthlyReport(&filter)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// @Summary Возвращает расходы на подписки, сгруппированные по сервису, пользователю или месяцу
+// @Description Для каждой группы считаются количество подписок, сумма, средняя, минимальная и максимальная стоимость подписки за период
+// @Tags Отчёты
+// @Produce json
+// @Param group_by query string true "Поле группировки" Enums(service_name, user_id, month)
+// @Param user_id query string false "ID пользователя" example("user12345")
+// @Param service_name query string false "Название сервиса" example("YouTube")
+// @Param start_date query string true "Месяц начала периода" example(01-2025)
+// @Param end_date query string true "Месяц окончания периода" example(12-2025)
+// @Param currency query string false "Валюта, в которую пересчитываются расходы по курсу месяца (ISO 4217)" example(RUB)
+// @Param mode query string false "Учёт подписок с периодом оплаты больше месяца: charged - в месяц списания, amortized - равными долями по месяцам" Enums(charged, amortized) default(charged)
+// @Param order_by query string false "Поле сортировки групп, - для убывания" Enums(key, -key, count, -count, sum, -sum, avg, -avg, min, -min, max, -max) default(-sum)
+// @Param limit query int false "Количество первых групп (0-100), 0 - все группы" default(0)
+// @Success 200 {object} models.AggregateReport "Сгруппированные расходы"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Пользователь не найден"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 422 {object} models.BadResponse "Подписки оплачиваются в разных валютах или нет курса для пересчёта"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/reports/aggregate [get]
+func AggregateSubscriptionsHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter models.AggregateFilter
+		if err := bindQuery(c, &filter); err != nil {
+			writeError(c, err)
+			return
+		}
+		report, err := s.Service.AggregateSubscriptions(&filter)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// @Summary Восстанавливает удалённую подписку по id
+// @Description Удалённая подписка хранится до очистки командой purge
+// @Tags Подписки
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Param X-Actor header string false "Кто выполняет изменение, записывается в журнал изменений" example(billing-service)
+// @Success 200 {object} models.Subscription "Восстановленная подписка"
+// @Header 200 {string} ETag "Версия подписки"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 409 {object} models.BadResponse "Подписка не удалена"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id}/restore [post]
+func RestoreSubscriptionHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sub, err := s.Service.Restore(c.Param("id"), auditMeta(c))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		setETag(c, sub)
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+// @Summary Возвращает журнал изменений подписки
+// @Description Записи о создании, изменениях, изменениях цены и удалении подписки в порядке их выполнения, журнал доступен и после удаления подписки
+// @Tags Подписки
+// @Produce json
+// @Param id path string true "ID подписки" format(uuid) example(550e8400-e29b-41d4-a716-446655440000)
+// @Success 200 {object} models.HistoryResponse "Журнал изменений"
+// @Failure 400 {object} models.BadResponse "Неверный формат запроса, в errors перечислены ошибочные поля"
+// @Failure 404 {object} models.BadResponse "Подписка не найдена"
+// @Failure 405 {object} models.BadResponse "Метод не разрешён"
+// @Failure 500 {object} models.BadResponse "Внутренняя ошибка сервера"
+// @Router /v2/subscriptions/{id}/history [get]
+func SubscriptionHistoryHandler(s *SubscriptionServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := s.Service.History(c.Param("id"))
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, models.HistoryResponse{Entries: entries})
+	}
+}
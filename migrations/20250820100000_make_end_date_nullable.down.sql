@@ -0,0 +1,2 @@
+UPDATE subscriptions SET end_date = GREATEST(start_date, date_trunc('month', CURRENT_DATE)::date) WHERE end_date IS NULL;
+ALTER TABLE subscriptions ALTER COLUMN end_date SET NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE subscriptions ALTER COLUMN end_date DROP NOT NULL;
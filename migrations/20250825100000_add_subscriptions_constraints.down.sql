@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS subscriptions_service_name_start_date_idx;
+DROP INDEX IF EXISTS subscriptions_user_id_start_date_idx;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_dates_check;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_price_check;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_pkey;
+ALTER TABLE subscriptions ALTER COLUMN id TYPE VARCHAR(255) USING id::text;
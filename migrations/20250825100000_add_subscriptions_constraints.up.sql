@@ -0,0 +1,6 @@
+ALTER TABLE subscriptions ALTER COLUMN id TYPE UUID USING id::uuid;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_pkey PRIMARY KEY (id);
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_price_check CHECK (price >= 0);
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_dates_check CHECK (end_date IS NULL OR end_date >= start_date);
+CREATE INDEX IF NOT EXISTS subscriptions_user_id_start_date_idx ON subscriptions (user_id, start_date);
+CREATE INDEX IF NOT EXISTS subscriptions_service_name_start_date_idx ON subscriptions (service_name, start_date);
@@ -0,0 +1,4 @@
+COMMENT ON COLUMN subscriptions.price IS NULL;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_currency_check;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS currency;
+ALTER TABLE subscriptions ALTER COLUMN price TYPE INT USING round(price / 100.0)::int;
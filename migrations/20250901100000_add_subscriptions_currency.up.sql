@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions ALTER COLUMN price TYPE BIGINT USING price::bigint * 100;
+ALTER TABLE subscriptions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+COMMENT ON COLUMN subscriptions.price IS 'amount in minor units of currency';
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL,
+    effective_from DATE NOT NULL,
+    CONSTRAINT exchange_rates_pkey PRIMARY KEY (base_currency, quote_currency, effective_from),
+    CONSTRAINT exchange_rates_rate_check CHECK (rate > 0),
+    CONSTRAINT exchange_rates_pair_check CHECK (base_currency <> quote_currency)
+);
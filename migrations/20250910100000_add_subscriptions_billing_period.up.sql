@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ADD COLUMN billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly';
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_billing_period_check
+    CHECK (billing_period IN ('weekly', 'monthly', 'quarterly', 'yearly'));
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL,
+    price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    CONSTRAINT subscription_prices_pkey PRIMARY KEY (subscription_id, effective_from),
+    CONSTRAINT subscription_prices_price_check CHECK (price >= 0),
+    CONSTRAINT subscription_prices_currency_check CHECK (currency ~ '^[A-Z]{3}$')
+);
+COMMENT ON TABLE subscription_prices IS 'price changes of subscriptions after their start month';
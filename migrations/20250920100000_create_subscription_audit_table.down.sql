@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS subscription_audit;
+DROP FUNCTION IF EXISTS subscription_audit_append_only();
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS subscription_audit (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    subscription_id UUID NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    before JSONB,
+    after JSONB,
+    CONSTRAINT subscription_audit_pkey PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS subscription_audit_subscription_id_idx ON subscription_audit (subscription_id, id);
+
+CREATE OR REPLACE FUNCTION subscription_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscription_audit_append_only
+    BEFORE UPDATE OR DELETE ON subscription_audit
+    FOR EACH ROW EXECUTE FUNCTION subscription_audit_append_only();
@@ -0,0 +1,9 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM subscriptions WHERE deleted_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'subscriptions has soft-deleted rows, restore or purge them before rolling back';
+    END IF;
+END;
+$$;
+DROP INDEX IF EXISTS subscriptions_deleted_at_idx;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS holds the SQL migrations so that they are shipped inside the binary.
+//
+//go:embed *.sql
+var FS embed.FS
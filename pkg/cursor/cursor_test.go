@@ -0,0 +1,48 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []Cursor{
+		{Sort: "price", Value: "39900", Id: "550e8400-e29b-41d4-a716-446655440000"},
+		{Sort: "-service_name", Value: "Яндекс Плюс", Id: "1"},
+		{Sort: "", Value: "", Id: "1"},
+	}
+	for _, want := range tests {
+		encoded := Encode(want)
+		got, err := Decode(encoded)
+		if err != nil {
+			t.Fatalf("Decode(Encode(%+v)) error = %v", want, err)
+		}
+		if got != want {
+			t.Errorf("Decode(Encode(%+v)) = %+v", want, got)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "not base64", input: "!!!"},
+		{name: "padded base64", input: base64.URLEncoding.EncodeToString([]byte(`{"id":"1"}`))},
+		{name: "not json", input: encode("price")},
+		{name: "wrong type", input: encode(`{"s":1,"id":"1"}`)},
+		{name: "without id", input: encode(`{"s":"price","v":"1"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.input); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q) error = %v, want ErrInvalidCursor", tt.input, err)
+			}
+		})
+	}
+}
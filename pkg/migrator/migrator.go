@@ -0,0 +1,120 @@
+package migrator
+
+import (
+	"TestEffectiveMobile/pkg/postgres"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+type Migrator struct {
+	m          *migrate.Migrate
+	migrations fs.FS
+}
+
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+func New(migrations fs.FS, config postgres.Config) (*Migrator, error) {
+	src, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	databaseURL := strings.Replace(config.ConnString(), "postgres://", "pgx5://", 1)
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create migrator: %w", err)
+	}
+	return &Migrator{
+		m:          m,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies all pending up-migrations.
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	return nil
+}
+
+// Down rolls back the last steps migrations.
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	if err := m.m.Steps(-steps); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot roll back %d migrations: %w", steps, err)
+		}
+		return fmt.Errorf("error rolling back migrations: %w", err)
+	}
+	return nil
+}
+
+// Version returns the currently applied version, zero if nothing is applied.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error reading migration version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Status lists every known migration and whether it is applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+	src, err := iofs.New(m.migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	defer src.Close()
+
+	var statuses []MigrationStatus
+	version, err := src.First()
+	for err == nil {
+		name, nameErr := migrationName(src, version)
+		if nameErr != nil {
+			return nil, nameErr
+		}
+		statuses = append(statuses, MigrationStatus{
+			Version: version,
+			Name:    name,
+			Applied: version <= current,
+		})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error listing migrations: %w", err)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+func migrationName(src source.Driver, version uint) (string, error) {
+	r, name, err := src.ReadUp(version)
+	if err != nil {
+		return "", fmt.Errorf("error reading migration %d: %w", version, err)
+	}
+	return name, r.Close()
+}
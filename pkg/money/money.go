@@ -0,0 +1,106 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// DefaultCurrency is the currency of prices given without one.
+const DefaultCurrency = "RUB"
+
+// minorUnits is the number of minor units (kopecks, cents) in a major one.
+const minorUnits = 100
+
+var (
+	currencyRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+	amountRegexp   = regexp.MustCompile(`^(\d+)(?:[.,](\d{1,2}))?$`)
+)
+
+// Money is an amount in minor units of an ISO 4217 currency.
+type Money struct {
+	Amount   int64  `json:"amount" example:"39900"`
+	Currency string `json:"currency" example:"RUB"`
+}
+
+// IsValidCurrency reports whether code looks like an ISO 4217 currency code.
+func IsValidCurrency(code string) bool {
+	return currencyRegexp.MatchString(code)
+}
+
+var (
+	// ErrInvalidMoney is returned by UnmarshalJSON for values that are neither
+	// an amount object nor a legacy integer price.
+	ErrInvalidMoney = errors.New("price must be an object with amount and currency or an integer")
+	// ErrAmountOutOfRange is returned by UnmarshalJSON for legacy prices that
+	// do not fit in minor units.
+	ErrAmountOutOfRange = errors.New("price is out of range")
+)
+
+// maxMajor is the largest amount in major units that fits in minor units.
+const maxMajor = (math.MaxInt64 - minorUnits) / minorUnits
+
+// UnmarshalJSON also accepts a bare integer, the legacy price format, as an
+// amount in major units. The currency is left empty for the service to fill
+// in: the currency of the subscription or DefaultCurrency for a new one.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		type plain Money
+		if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+			return ErrInvalidMoney
+		}
+		return nil
+	}
+	var major int64
+	if err := json.Unmarshal(data, &major); err != nil {
+		return ErrInvalidMoney
+	}
+	if major > maxMajor || major < -maxMajor {
+		return ErrAmountOutOfRange
+	}
+	m.Amount = major * minorUnits
+	return nil
+}
+
+// MajorUnits converts an amount in minor units to whole major units, the
+// legacy price format, dropping the fraction.
+func MajorUnits(amount int64) int64 {
+	return amount / minorUnits
+}
+
+// ParseAmount parses an amount in major units with up to two decimals, such
+// as "399.99" or "399,99", into minor units.
+func ParseAmount(s string) (int64, bool) {
+	match := amountRegexp.FindStringSubmatch(s)
+	if match == nil {
+		return 0, false
+	}
+	major, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || major > maxMajor {
+		return 0, false
+	}
+	var minor int64
+	if match[2] != "" {
+		minor, _ = strconv.ParseInt(match[2], 10, 64)
+		if len(match[2]) == 1 {
+			minor *= 10
+		}
+	}
+	return major*minorUnits + minor, true
+}
+
+// FormatAmount renders an amount in minor units in major units with two
+// decimals, the format read by ParseAmount.
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/minorUnits, amount%minorUnits)
+}
@@ -0,0 +1,133 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		amount int64
+		ok     bool
+	}{
+		{name: "whole", input: "399", amount: 39900, ok: true},
+		{name: "two decimals", input: "399.99", amount: 39999, ok: true},
+		{name: "comma", input: "399,99", amount: 39999, ok: true},
+		{name: "one decimal", input: "399.9", amount: 39990, ok: true},
+		{name: "zero", input: "0", amount: 0, ok: true},
+		{name: "largest", input: "92233720368547756", amount: 9223372036854775600, ok: true},
+		{name: "overflow", input: "92233720368547759", ok: false},
+		{name: "three decimals", input: "1.999", ok: false},
+		{name: "negative", input: "-1", ok: false},
+		{name: "empty", input: "", ok: false},
+		{name: "not a number", input: "abc", ok: false},
+		{name: "dot only", input: "1.", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, ok := ParseAmount(tt.input)
+			if ok != tt.ok || amount != tt.amount {
+				t.Errorf("ParseAmount(%q) = %d, %v, want %d, %v", tt.input, amount, ok, tt.amount, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   string
+	}{
+		{amount: 39999, want: "399.99"},
+		{amount: 39900, want: "399.00"},
+		{amount: 5, want: "0.05"},
+		{amount: -150, want: "-1.50"},
+	}
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount); got != tt.want {
+			t.Errorf("FormatAmount(%d) = %q, want %q", tt.amount, got, tt.want)
+		}
+		if tt.amount < 0 {
+			continue
+		}
+		if parsed, ok := ParseAmount(FormatAmount(tt.amount)); !ok || parsed != tt.amount {
+			t.Errorf("ParseAmount(FormatAmount(%d)) = %d, %v", tt.amount, parsed, ok)
+		}
+	}
+}
+
+func TestIsValidCurrency(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code: "RUB", want: true},
+		{code: "USD", want: true},
+		{code: "usd", want: false},
+		{code: "RUBL", want: false},
+		{code: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsValidCurrency(tt.code); got != tt.want {
+			t.Errorf("IsValidCurrency(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Money
+		err   error
+	}{
+		{name: "object", input: `{"amount":39999,"currency":"USD"}`, want: Money{Amount: 39999, Currency: "USD"}},
+		{name: "object without currency", input: `{"amount":100}`, want: Money{Amount: 100}},
+		{name: "legacy integer", input: `399`, want: Money{Amount: 39900}},
+		{name: "legacy negative", input: `-5`, want: Money{Amount: -500}},
+		{name: "null", input: `null`, want: Money{}},
+		{name: "legacy overflow", input: `92233720368547759`, err: ErrAmountOutOfRange},
+		{name: "legacy overflow wrapping to kopecks", input: `184467440737095517`, err: ErrAmountOutOfRange},
+		{name: "legacy negative overflow", input: `-92233720368547759`, err: ErrAmountOutOfRange},
+		{name: "string", input: `"x"`, err: ErrInvalidMoney},
+		{name: "fraction", input: `1.5`, err: ErrInvalidMoney},
+		{name: "bad amount", input: `{"amount":"x"}`, err: ErrInvalidMoney},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Unmarshal(%s) error = %v, want %v", tt.input, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMajorUnits(t *testing.T) {
+	tests := []struct {
+		amount int64
+		want   int64
+	}{
+		{amount: 39900, want: 399},
+		{amount: 39999, want: 399},
+		{amount: 99, want: 0},
+	}
+	for _, tt := range tests {
+		if got := MajorUnits(tt.amount); got != tt.want {
+			t.Errorf("MajorUnits(%d) = %d, want %d", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -3,27 +3,48 @@ package postgres
 import (
 	"context"
 	"fmt"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
 )
 
 type Config struct {
-	Host     string `yaml:"postgres_host" env:"POSTGRES_HOST" env-default:"localhost"`
-	Port     string `yaml:"postgres_port" env:"POSTGRES_PORT" env-default:"5434"`
-	Database string `yaml:"postgres_db" env:"POSTGRES_DB" env-default:"postgres"`
-	User     string `yaml:"postgres_user" env:"POSTGRES_USER" env-default:"root"`
-	Password string `yaml:"postgres_password" env:"POSTGRES_PASSWORD" env-default:"1234"`
+	Host              string        `yaml:"postgres_host" env:"POSTGRES_HOST" env-default:"localhost"`
+	Port              string        `yaml:"postgres_port" env:"POSTGRES_PORT" env-default:"5434"`
+	Database          string        `yaml:"postgres_db" env:"POSTGRES_DB" env-default:"postgres"`
+	User              string        `yaml:"postgres_user" env:"POSTGRES_USER" env-default:"root"`
+	Password          string        `yaml:"postgres_password" env:"POSTGRES_PASSWORD" env-default:"1234"`
+	MaxConns          int32         `yaml:"postgres_max_conns" env:"POSTGRES_MAX_CONNS" env-default:"10"`
+	MinConns          int32         `yaml:"postgres_min_conns" env:"POSTGRES_MIN_CONNS" env-default:"2"`
+	MaxConnLifetime   time.Duration `yaml:"postgres_max_conn_lifetime" env:"POSTGRES_MAX_CONN_LIFETIME" env-default:"1h"`
+	HealthCheckPeriod time.Duration `yaml:"postgres_health_check_period" env:"POSTGRES_HEALTH_CHECK_PERIOD" env-default:"1m"`
 }
 
-func New(config Config) (*pgx.Conn, error) {
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
-		config.User,
-		config.Password,
-		config.Host,
-		config.Port,
-		config.Database)
-	conn, err := pgx.Connect(context.Background(), connString)
+func (c Config) ConnString() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
+		c.User,
+		c.Password,
+		c.Host,
+		c.Port,
+		c.Database)
+}
+
+func New(config Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(config.ConnString())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse database config: %w", err)
+	}
+	poolConfig.MaxConns = config.MaxConns
+	poolConfig.MinConns = config.MinConns
+	poolConfig.MaxConnLifetime = config.MaxConnLifetime
+	poolConfig.HealthCheckPeriod = config.HealthCheckPeriod
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("unable to connect to database: %w", err)
 	}
-	return conn, nil
+	return pool, nil
 }
@@ -1,8 +1,86 @@
 package suberrors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrIdSubscriptionNotFound = errors.New("subscription id not found")
 	ErrUserIdNotFound         = errors.New("user id not found")
+	ErrSubscriptionConflict   = errors.New("subscription conflicts with an existing one")
+	ErrConstraintViolation    = errors.New("subscription violates a constraint")
+	ErrMixedCurrencies        = errors.New("subscriptions are billed in different currencies")
+	ErrExchangeRateNotFound   = errors.New("exchange rate not found")
+	ErrVersionConflict        = errors.New("subscription was modified by another request")
+	ErrPreconditionRequired   = errors.New("If-Match header is required")
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyInProgress  = errors.New("request with this idempotency key is still in progress")
+	ErrBulkAborted            = errors.New("not applied because another item of the atomic batch failed")
+	ErrSubscriptionNotDeleted = errors.New("subscription is not deleted")
+)
+
+// ConstraintError reports the storage constraint that rejected a write.
+// It unwraps to ErrSubscriptionConflict for unique violations and to
+// ErrConstraintViolation for check violations.
+type ConstraintError struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err, e.Constraint)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+var ErrValidation = errors.New("validation failed")
+
+// Codes of FieldError.
+const (
+	CodeRequired      = "required"
+	CodeInvalidFormat = "invalid_format"
+	CodeInvalidType   = "invalid_type"
+	CodeOutOfRange    = "out_of_range"
+	CodeInvalidRange  = "invalid_range"
 )
+
+// FieldError describes a problem with a single input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every FieldError found in a request, it unwraps
+// to ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Add(field string, code string, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// OrNil returns e as an error if it holds any field problems and nil otherwise.
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		problems = append(problems, field.Field+": "+field.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,23 @@
+package suberrors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	verr := &ValidationError{}
+	if err := verr.OrNil(); err != nil {
+		t.Errorf("OrNil() of an empty ValidationError = %v, want nil", err)
+	}
+	verr.Add("price", CodeRequired, "price is required")
+	verr.Add("start_date", CodeInvalidFormat, "start_date must be in MM-YYYY format")
+	err := verr.OrNil()
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("OrNil() = %v, want an error wrapping ErrValidation", err)
+	}
+	want := "validation failed: price: price is required; start_date: start_date must be in MM-YYYY format"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -12,3 +12,16 @@ func ParseMonthYear(monthYear string) (time.Time, error) {
 	}
 	return time.Date(parsed.Year(), parsed.Month(), 1, 0, 0, 0, 0, time.UTC), nil
 }
+
+// ParseOptionalMonthYear treats an empty string as an absent date, e.g. the end
+// of an open-ended subscription.
+func ParseOptionalMonthYear(monthYear string) (*time.Time, error) {
+	if monthYear == "" {
+		return nil, nil
+	}
+	parsed, err := ParseMonthYear(monthYear)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,40 @@
+package timeparser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMonthYear(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+		ok    bool
+	}{
+		{input: "07-2025", want: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), ok: true},
+		{input: "12-2030", want: time.Date(2030, time.December, 1, 0, 0, 0, 0, time.UTC), ok: true},
+		{input: "13-2025", ok: false},
+		{input: "2025-07", ok: false},
+		{input: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, err := ParseMonthYear(tt.input)
+		if (err == nil) != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("ParseMonthYear(%q) = %v, %v, want %v, ok %v", tt.input, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseOptionalMonthYear(t *testing.T) {
+	got, err := ParseOptionalMonthYear("")
+	if got != nil || err != nil {
+		t.Errorf("ParseOptionalMonthYear(\"\") = %v, %v, want nil, nil", got, err)
+	}
+	got, err = ParseOptionalMonthYear("03-2026")
+	if err != nil || got == nil || !got.Equal(time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseOptionalMonthYear(\"03-2026\") = %v, %v", got, err)
+	}
+	if _, err := ParseOptionalMonthYear("3-2026"); err == nil {
+		t.Errorf("ParseOptionalMonthYear(\"3-2026\") error = nil")
+	}
+}